@@ -0,0 +1,126 @@
+package grpcserver
+
+import (
+	"context"
+	"net"
+	"net/http"
+
+	"github.com/realmallaury/teltech/cmd/handler"
+	"github.com/realmallaury/teltech/internal/auth"
+	"github.com/realmallaury/teltech/internal/logging"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// Metadata keys of call credentials, same as HTTP headers.
+const (
+	authorizationKey string = "authorization"
+	apiKeyKey        string = "x-api-key"
+)
+
+type contextKey int
+
+// apiKeyContextKey is context key of API key used to authenticate call.
+const apiKeyContextKey contextKey = iota
+
+// authInterceptor validates bearer token or API key from call metadata and requires arithmetic:read scope,
+// nil authenticator disables authentication.
+func authInterceptor(authenticator *handler.Authenticator, logger *logging.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, next grpc.UnaryHandler) (interface{}, error) {
+		if authenticator == nil {
+			return next(ctx, req)
+		}
+
+		identity, key, code, err := authenticator.Validate(metadataValue(ctx, authorizationKey), metadataValue(ctx, apiKeyKey))
+		if err != nil {
+			if code == http.StatusInternalServerError {
+				logger.Errorf("gRPC authentication error: %v", err)
+				return nil, status.Error(codes.Internal, "could not validate API key")
+			}
+
+			return nil, status.Error(codes.Unauthenticated, err.Error())
+		}
+
+		if !identity.HasScope(auth.ScopeArithmeticRead) {
+			return nil, status.Errorf(codes.PermissionDenied, "missing scope: %s", auth.ScopeArithmeticRead)
+		}
+
+		ctx = auth.ContextWithIdentity(ctx, identity)
+		if key != nil {
+			ctx = context.WithValue(ctx, apiKeyContextKey, *key)
+		}
+
+		return next(ctx, req)
+	}
+}
+
+// rateLimitInterceptor rejects calls exceeding client limit for the method, method limits are set
+// by full method name, e.g. /teltech.arithmetic.v1.Arithmetic/Batch. Operations of the call are
+// limited separately by calculator.
+func rateLimitInterceptor(rateLimiter *handler.RateLimiter, logger *logging.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, next grpc.UnaryHandler) (interface{}, error) {
+		if rateLimiter == nil {
+			return next(ctx, req)
+		}
+
+		result, err := rateLimiter.Take(rateLimitClient(ctx), info.FullMethod)
+		if err != nil {
+			// Backend failures should not block clients.
+			logger.Errorf("gRPC rate limiter error: %v", err)
+		}
+
+		if !result.Allowed {
+			return nil, status.Error(codes.ResourceExhausted, handler.RateLimitError(result).Error())
+		}
+
+		return next(ctx, req)
+	}
+}
+
+// callerInterceptor charges each arithmetic operation of call to client rate limit of the operation,
+// e.g. /multiply, and API key quota, so batches and expressions are charged same as HTTP requests.
+func callerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, next grpc.UnaryHandler) (interface{}, error) {
+		caller := handler.Caller{Client: rateLimitClient(ctx)}
+		if key, ok := ctx.Value(apiKeyContextKey).(auth.Key); ok {
+			caller.Key = &key
+		}
+
+		return next(handler.ContextWithCaller(ctx, caller), req)
+	}
+}
+
+// rateLimitClient identifies client by authenticated identity or peer IP address.
+func rateLimitClient(ctx context.Context) string {
+	if identity, ok := auth.IdentityFromContext(ctx); ok {
+		return "id:" + identity.Name
+	}
+
+	if p, ok := peer.FromContext(ctx); ok {
+		if host, _, err := net.SplitHostPort(p.Addr.String()); err == nil {
+			return "ip:" + host
+		}
+
+		return "ip:" + p.Addr.String()
+	}
+
+	return "ip:"
+}
+
+// metadataValue returns first value of incoming metadata key.
+func metadataValue(ctx context.Context, key string) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+
+	if values := md.Get(key); len(values) > 0 {
+		return values[0]
+	}
+
+	return ""
+}
@@ -0,0 +1,186 @@
+package grpcserver
+
+import (
+	"context"
+	"crypto/tls"
+	"time"
+
+	"github.com/realmallaury/teltech/cmd/handler"
+	"github.com/realmallaury/teltech/internal/arithmetic"
+	"github.com/realmallaury/teltech/internal/arithmeticpb"
+	"github.com/realmallaury/teltech/internal/logging"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/status"
+)
+
+// maxBatchSize is maximum number of operations in batch request.
+const maxBatchSize int = 100
+
+// ArithmeticServer implements gRPC arithmetic service using the same cache as HTTP endpoints.
+type ArithmeticServer struct {
+	arithmeticpb.UnimplementedArithmeticServer
+
+	calculator *handler.Calculator
+}
+
+type options struct {
+	authenticator *handler.Authenticator
+	rateLimiter   *handler.RateLimiter
+	tlsConfig     *tls.Config
+}
+
+// Option configures gRPC server.
+type Option func(*options)
+
+// WithAuthenticator sets authenticator validating API key or bearer token from call metadata
+// and enforcing arithmetic:read scope.
+func WithAuthenticator(authenticator *handler.Authenticator) Option {
+	return func(o *options) {
+		o.authenticator = authenticator
+	}
+}
+
+// WithRateLimiter sets rate limiter limiting calls per client and method.
+func WithRateLimiter(rateLimiter *handler.RateLimiter) Option {
+	return func(o *options) {
+		o.rateLimiter = rateLimiter
+	}
+}
+
+// WithTLSConfig serves gRPC with TLS config, nil config serves plain connections.
+func WithTLSConfig(tlsConfig *tls.Config) Option {
+	return func(o *options) {
+		o.tlsConfig = tlsConfig
+	}
+}
+
+// New creates gRPC server with registered arithmetic service, logging and security interceptors,
+// operations are charged to rate limiter and authenticator of calculator.
+func New(logger *logging.Logger, calculator *handler.Calculator, opts ...Option) *grpc.Server {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	serverOpts := []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(
+			loggingInterceptor(logger),
+			authInterceptor(o.authenticator, logger),
+			rateLimitInterceptor(o.rateLimiter, logger),
+			callerInterceptor(),
+		),
+	}
+
+	if o.tlsConfig != nil {
+		serverOpts = append(serverOpts, grpc.Creds(credentials.NewTLS(o.tlsConfig)))
+	}
+
+	server := grpc.NewServer(serverOpts...)
+
+	arithmeticpb.RegisterArithmeticServer(server, &ArithmeticServer{
+		calculator: calculator,
+	})
+
+	return server
+}
+
+// Calculate returns result of single arithmetic operation.
+func (as *ArithmeticServer) Calculate(ctx context.Context, req *arithmeticpb.CalculateRequest) (*arithmeticpb.Result, error) {
+	result, err := as.calculator.Calculate(ctx, req.Action, req.X, req.Y)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return toProto(result), nil
+}
+
+// Batch returns results of multiple arithmetic operations.
+func (as *ArithmeticServer) Batch(ctx context.Context, req *arithmeticpb.BatchRequest) (*arithmeticpb.BatchResponse, error) {
+	if len(req.Operations) > maxBatchSize {
+		return nil, status.Errorf(codes.InvalidArgument, "batch size: %d exceeds maximum: %d", len(req.Operations), maxBatchSize)
+	}
+
+	resp := &arithmeticpb.BatchResponse{
+		Results: make([]*arithmeticpb.BatchResult, 0, len(req.Operations)),
+	}
+
+	for _, operation := range req.Operations {
+		result, err := as.calculator.Calculate(ctx, operation.Action, operation.X, operation.Y)
+		if err != nil {
+			resp.Results = append(resp.Results, &arithmeticpb.BatchResult{Error: err.Error()})
+			continue
+		}
+
+		resp.Results = append(resp.Results, &arithmeticpb.BatchResult{Result: toProto(result)})
+	}
+
+	return resp, nil
+}
+
+// Evaluate returns answer of arithmetic expression.
+func (as *ArithmeticServer) Evaluate(ctx context.Context, req *arithmeticpb.EvaluateRequest) (*arithmeticpb.EvaluateResponse, error) {
+	result, err := as.calculator.Evaluate(ctx, req.Expression)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &arithmeticpb.EvaluateResponse{
+		Expression: result.Expression,
+		Answer:     result.Answer,
+		Cached:     result.Cached,
+	}, nil
+}
+
+// toStatus returns status of calculator error, operations rejected by rate limit or quota
+// are resource exhausted.
+func toStatus(err error) error {
+	if handler.IsLimitError(err) {
+		return status.Error(codes.ResourceExhausted, err.Error())
+	}
+
+	return status.Error(codes.InvalidArgument, err.Error())
+}
+
+func toProto(result *arithmetic.Result) *arithmeticpb.Result {
+	return &arithmeticpb.Result{
+		Action:      result.Action,
+		X:           result.X,
+		Y:           result.Y,
+		Answer:      result.Answer,
+		Numerator:   result.Numerator,
+		Denominator: result.Denominator,
+		Decimal:     result.Decimal,
+		Cached:      result.Cached,
+	}
+}
+
+// loggingInterceptor writes structured log line for each unary call.
+func loggingInterceptor(logger *logging.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		start := time.Now()
+
+		resp, err := handler(ctx, req)
+
+		level := logging.InfoLevel
+		if status.Code(err) == codes.Internal || status.Code(err) == codes.Unknown {
+			level = logging.ErrorLevel
+		}
+
+		fields := logging.Fields{
+			"method":     info.FullMethod,
+			"code":       status.Code(err).String(),
+			"latency_ms": float64(time.Since(start).Microseconds()) / 1000,
+		}
+
+		if err != nil {
+			fields["error"] = status.Convert(err).Message()
+		}
+
+		logger.Log(level, "grpc request", fields)
+
+		return resp, err
+	}
+}
@@ -0,0 +1,254 @@
+package grpcserver
+
+import (
+	"context"
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/realmallaury/teltech/cmd/handler"
+	"github.com/realmallaury/teltech/internal/arithmeticpb"
+	"github.com/realmallaury/teltech/internal/auth"
+	"github.com/realmallaury/teltech/internal/cache"
+	"github.com/realmallaury/teltech/internal/logging"
+	"github.com/realmallaury/teltech/internal/ratelimit"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func getTestClient(t *testing.T, calculator *handler.Calculator, opts ...Option) (arithmeticpb.ArithmeticClient, func()) {
+	logger := logging.New(os.Stdout, logging.DebugLevel)
+	if calculator == nil {
+		calculator = handler.NewCalculator(logger, cache.NewStore(10, time.Minute))
+	}
+
+	listener := bufconn.Listen(1024 * 1024)
+	server := New(logger, calculator, opts...)
+	go server.Serve(listener)
+
+	dialer := func(context.Context, string) (net.Conn, error) {
+		return listener.Dial()
+	}
+
+	conn, err := grpc.DialContext(context.Background(), "bufnet", grpc.WithContextDialer(dialer), grpc.WithInsecure())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return arithmeticpb.NewArithmeticClient(conn), func() {
+		conn.Close()
+		server.Stop()
+	}
+}
+
+func TestCalculate(t *testing.T) {
+	assert := assert.New(t)
+
+	client, cleanup := getTestClient(t, nil)
+	defer cleanup()
+
+	tests := []struct {
+		action string
+		x      string
+		y      string
+		answer string
+		cached bool
+		code   codes.Code
+	}{
+		{"add", "1", "2", "3", false, codes.OK},
+		{"add", "1", "2", "3", true, codes.OK},
+		{"subtract", "5", "2", "3", false, codes.OK},
+		{"multiply", "2", "2.5", "5", false, codes.OK},
+		{"divide", "1", "4", "0.25", false, codes.OK},
+		{"divide", "1", "0", "+Inf", false, codes.OK},
+		{"add", "0xff", "1", "256", false, codes.OK},
+		{"add", "1/3", "1/6", "1/2", false, codes.OK},
+		{"divide", "1/3", "0", "", false, codes.InvalidArgument},
+		{"add", "a", "2", "", false, codes.InvalidArgument},
+		{"modulo", "1", "2", "", false, codes.InvalidArgument},
+	}
+
+	for _, test := range tests {
+		result, err := client.Calculate(context.Background(), &arithmeticpb.CalculateRequest{
+			Action: test.action,
+			X:      test.x,
+			Y:      test.y,
+		})
+
+		assert.Equal(test.code, status.Code(err))
+		if test.code != codes.OK {
+			continue
+		}
+
+		assert.Equal(test.action, result.Action)
+		assert.Equal(test.answer, result.Answer)
+		assert.Equal(test.cached, result.Cached)
+	}
+}
+
+func TestBatch(t *testing.T) {
+	assert := assert.New(t)
+
+	client, cleanup := getTestClient(t, nil)
+	defer cleanup()
+
+	resp, err := client.Batch(context.Background(), &arithmeticpb.BatchRequest{
+		Operations: []*arithmeticpb.CalculateRequest{
+			{Action: "add", X: "1", Y: "2"},
+			{Action: "modulo", X: "1", Y: "2"},
+			{Action: "multiply", X: "3", Y: "b"},
+		},
+	})
+
+	assert.NoError(err)
+	assert.Len(resp.Results, 3)
+	assert.Equal("3", resp.Results[0].Result.Answer)
+	assert.Empty(resp.Results[0].Error)
+	assert.Nil(resp.Results[1].Result)
+	assert.Equal("unknown action: modulo", resp.Results[1].Error)
+	assert.Equal("y value: b not valid number", resp.Results[2].Error)
+
+	_, err = client.Batch(context.Background(), &arithmeticpb.BatchRequest{
+		Operations: make([]*arithmeticpb.CalculateRequest, maxBatchSize+1),
+	})
+	assert.Equal(codes.InvalidArgument, status.Code(err))
+}
+
+func TestEvaluate(t *testing.T) {
+	assert := assert.New(t)
+
+	client, cleanup := getTestClient(t, nil)
+	defer cleanup()
+
+	tests := []struct {
+		expression string
+		answer     string
+		cached     bool
+		code       codes.Code
+		err        string
+	}{
+		{"(1 + 2) * 0x10", "48", false, codes.OK, ""},
+		{"(1 + 2) * 0x10", "48", true, codes.OK, ""},
+		{"1 + (2 - 3", "", false, codes.InvalidArgument, "missing closing parenthesis for parenthesis at position 5"},
+		{"1 / (0x1 - 1)", "", false, codes.InvalidArgument, "operator / at position 3: integer division by zero"},
+	}
+
+	for _, test := range tests {
+		resp, err := client.Evaluate(context.Background(), &arithmeticpb.EvaluateRequest{Expression: test.expression})
+
+		assert.Equal(test.code, status.Code(err), test.expression)
+		if test.code != codes.OK {
+			assert.Equal(test.err, status.Convert(err).Message(), test.expression)
+			continue
+		}
+
+		assert.Equal(test.expression, resp.Expression)
+		assert.Equal(test.answer, resp.Answer, test.expression)
+		assert.Equal(test.cached, resp.Cached, test.expression)
+	}
+}
+
+func TestSecurity(t *testing.T) {
+	assert := assert.New(t)
+
+	authenticator := handler.NewAuthenticator(
+		auth.NewMemoryKeyStore([]auth.Key{
+			{Key: "reader", Name: "reader", Scopes: []string{auth.ScopeArithmeticRead}, DailyQuota: 3},
+			{Key: "admin", Name: "admin", Scopes: []string{auth.ScopeAdminUsage}},
+			{Key: "limited", Name: "limited", Scopes: []string{auth.ScopeArithmeticRead}},
+		}),
+		auth.NewMemoryUsageStore(),
+		nil,
+	)
+
+	rateLimiter := handler.NewRateLimiter(
+		ratelimit.NewMemoryBackend(time.Minute),
+		ratelimit.Limit{},
+		map[string]ratelimit.Limit{
+			"/teltech.arithmetic.v1.Arithmetic/Batch": {Rate: 0.5, Burst: 1},
+			"/multiply": {Rate: 0.001, Burst: 2},
+		},
+	)
+
+	calculator := handler.NewCalculator(
+		logging.New(os.Stdout, logging.DebugLevel),
+		cache.NewStore(10, time.Minute),
+		handler.WithRateLimiter(rateLimiter),
+		handler.WithAuthenticator(authenticator),
+	)
+
+	client, cleanup := getTestClient(t, calculator, WithAuthenticator(authenticator), WithRateLimiter(rateLimiter))
+	defer cleanup()
+
+	withKey := func(apiKey string) context.Context {
+		if apiKey == "" {
+			return context.Background()
+		}
+
+		return metadata.AppendToOutgoingContext(context.Background(), "x-api-key", apiKey)
+	}
+
+	calculate := func(apiKey, action string) error {
+		_, err := client.Calculate(withKey(apiKey), &arithmeticpb.CalculateRequest{Action: action, X: "1", Y: "2"})
+		return err
+	}
+
+	batch := func(apiKey string, operations ...*arithmeticpb.CalculateRequest) ([]string, error) {
+		resp, err := client.Batch(withKey(apiKey), &arithmeticpb.BatchRequest{Operations: operations})
+
+		var errs []string
+		for _, result := range resp.GetResults() {
+			errs = append(errs, result.Error)
+		}
+
+		return errs, err
+	}
+
+	evaluate := func(apiKey, expression string) error {
+		_, err := client.Evaluate(withKey(apiKey), &arithmeticpb.EvaluateRequest{Expression: expression})
+		return err
+	}
+
+	multiply := &arithmeticpb.CalculateRequest{Action: "multiply", X: "2", Y: "3"}
+
+	// Test that each operation of batch is charged to operation rate limit
+	errs, err := batch("limited", multiply, multiply, multiply)
+	assert.NoError(err, "Error should be nil")
+	assert.Equal([]string{"", "", "rate limit exceeded, retry in 1000 seconds"}, errs)
+
+	tests := []struct {
+		name string
+		call func() error
+		code codes.Code
+		err  string
+	}{
+		{"missing credentials", func() error { return calculate("", "add") }, codes.Unauthenticated, "missing API key or bearer token"},
+		{"invalid API key", func() error { return calculate("invalid", "add") }, codes.Unauthenticated, "invalid API key"},
+		{"missing scope", func() error { return calculate("admin", "add") }, codes.PermissionDenied, "missing scope: arithmetic:read"},
+		{"valid API key", func() error { return calculate("reader", "add") }, codes.OK, ""},
+		{"method rate limit exceeded", func() error { _, err := batch("limited"); return err }, codes.ResourceExhausted, "rate limit exceeded, retry in 2 seconds"},
+		{"other client limit", func() error { _, err := batch("reader"); return err }, codes.OK, ""},
+		{"operation rate limit exceeded", func() error { return calculate("limited", "multiply") }, codes.ResourceExhausted, "rate limit exceeded, retry in 1000 seconds"},
+		{
+			"expression operation rate limit exceeded",
+			func() error { return evaluate("limited", "1 + 2 * 3") },
+			codes.ResourceExhausted,
+			"operator * at position 7: rate limit exceeded, retry in 1000 seconds",
+		},
+		{"expression quota", func() error { return evaluate("reader", "1 + 2 + 3") }, codes.OK, ""},
+		{"quota exceeded", func() error { return calculate("reader", "add") }, codes.ResourceExhausted, "daily quota exceeded"},
+	}
+
+	for _, test := range tests {
+		err := test.call()
+		assert.Equal(test.code, status.Code(err), test.name)
+		if test.code != codes.OK {
+			assert.Equal(test.err, status.Convert(err).Message(), test.name)
+		}
+	}
+}
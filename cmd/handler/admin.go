@@ -0,0 +1,45 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+	"github.com/realmallaury/teltech/internal/cache"
+)
+
+// AdminHandler holds data for handling cache administration requests.
+type AdminHandler struct {
+	store cache.Store
+}
+
+// CacheStats returns cache usage counters.
+func (ah *AdminHandler) CacheStats(c *gin.Context) {
+	provider, ok := ah.store.(cache.StatsProvider)
+	if !ok {
+		errorResponse(c, http.StatusNotImplemented, errors.New("cache store does not provide stats"))
+		return
+	}
+
+	stats := provider.Stats()
+
+	c.JSON(http.StatusOK, gin.H{
+		"hits":      stats.Hits,
+		"misses":    stats.Misses,
+		"evictions": stats.Evictions,
+		"entries":   stats.Len,
+	})
+}
+
+// PurgeCache removes all cached results.
+func (ah *AdminHandler) PurgeCache(c *gin.Context) {
+	purger, ok := ah.store.(cache.Purger)
+	if !ok {
+		errorResponse(c, http.StatusNotImplemented, errors.New("cache store does not support purge"))
+		return
+	}
+
+	purger.Purge()
+
+	c.Status(http.StatusNoContent)
+}
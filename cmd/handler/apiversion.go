@@ -0,0 +1,136 @@
+package handler
+
+import (
+	"encoding/xml"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+	"github.com/realmallaury/teltech/internal/arithmetic"
+	"github.com/realmallaury/teltech/internal/utils"
+)
+
+// API versions.
+const (
+	APIVersion1 string = "v1"
+	APIVersion2 string = "v2"
+
+	// DefaultAPIVersion is used by legacy routes when client doesn't request version in Accept header.
+	DefaultAPIVersion string = APIVersion1
+)
+
+// APIVersionHeader is response header with API version used to serialize response.
+const APIVersionHeader string = "API-Version"
+
+// Legacy route deprecation headers.
+const (
+	DeprecationHeader string = "Deprecation"
+	SunsetHeader      string = "Sunset"
+	LinkHeader        string = "Link"
+)
+
+// Legacy unversioned routes are deprecated in favour of versioned calc endpoint.
+var (
+	legacyDeprecatedAt = time.Date(2026, time.October, 19, 0, 0, 0, 0, time.UTC)
+	legacySunsetAt     = time.Date(2027, time.April, 19, 0, 0, 0, 0, time.UTC)
+)
+
+// rxVersionMediaType matches vendor media type with API version, e.g. application/vnd.teltech.v2+json.
+var rxVersionMediaType = regexp.MustCompile(`^application/vnd\.teltech\.(v[0-9]+)\+json$`)
+
+// resultSerializer converts result to response body of API version.
+type resultSerializer func(result arithmetic.Result) interface{}
+
+// resultV2 is version 2 result with operands formatted as strings, so they are not
+// rounded by clients which parse JSON numbers as float64.
+type resultV2 struct {
+	XMLName xml.Name `json:"-" xml:"result"`
+	Action  string   `json:"action" xml:"action"`
+	X       string   `json:"x" xml:"x"`
+	Y       string   `json:"y" xml:"y"`
+	Answer  string   `json:"answer" xml:"answer"`
+	// Numerator, Denominator and Decimal approximation are set by rational arithmetic.
+	Numerator   string `json:"numerator,omitempty" xml:"numerator,omitempty"`
+	Denominator string `json:"denominator,omitempty" xml:"denominator,omitempty"`
+	Decimal     string `json:"decimal,omitempty" xml:"decimal,omitempty"`
+	Cached      bool   `json:"cached" xml:"cached"`
+}
+
+// exactOperands returns operands of result as strings, integer and rational operands are exact.
+func exactOperands(result arithmetic.Result) (string, string) {
+	x, y := result.XExact, result.YExact
+	if x == "" {
+		x = utils.FloatToString(result.X)
+	}
+
+	if y == "" {
+		y = utils.FloatToString(result.Y)
+	}
+
+	return x, y
+}
+
+var resultSerializers = map[string]resultSerializer{
+	APIVersion1: func(result arithmetic.Result) interface{} {
+		return result
+	},
+	APIVersion2: func(result arithmetic.Result) interface{} {
+		x, y := exactOperands(result)
+
+		return resultV2{
+			Action:      result.Action,
+			X:           x,
+			Y:           y,
+			Answer:      result.Answer,
+			Numerator:   result.Numerator,
+			Denominator: result.Denominator,
+			Decimal:     result.Decimal,
+			Cached:      result.Cached,
+		}
+	},
+}
+
+// withAPIVersion sets API version of versioned routes.
+func withAPIVersion(version string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(versionKey, version)
+		c.Header(APIVersionHeader, version)
+		c.Next()
+	}
+}
+
+// negotiateAPIVersion sets API version requested with vendor media type in Accept header,
+// e.g. application/vnd.teltech.v2+json, or default version.
+func negotiateAPIVersion(c *gin.Context) {
+	version := DefaultAPIVersion
+
+	for _, mediaType := range strings.Split(c.GetHeader("Accept"), ",") {
+		mediaType = strings.TrimSpace(strings.SplitN(mediaType, ";", 2)[0])
+
+		if match := rxVersionMediaType.FindStringSubmatch(mediaType); match != nil {
+			if _, ok := resultSerializers[match[1]]; !ok {
+				errorResponse(c, http.StatusNotAcceptable, errors.Errorf("unsupported API version: %s", match[1]))
+				return
+			}
+
+			version = match[1]
+			break
+		}
+	}
+
+	c.Set(versionKey, version)
+	c.Header(APIVersionHeader, version)
+	c.Next()
+}
+
+// deprecated sets Deprecation, Sunset and successor Link headers on legacy routes.
+func deprecated(c *gin.Context) {
+	c.Header(DeprecationHeader, "@"+strconv.FormatInt(legacyDeprecatedAt.Unix(), 10))
+	c.Header(SunsetHeader, legacySunsetAt.Format(http.TimeFormat))
+	c.Header(LinkHeader, `</`+DefaultAPIVersion+`/calc`+c.FullPath()+`>; rel="successor-version"`)
+	c.Next()
+}
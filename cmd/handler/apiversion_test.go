@@ -0,0 +1,120 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/realmallaury/teltech/internal/cache"
+	"github.com/realmallaury/teltech/internal/logging"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIVersion(t *testing.T) {
+	assert := assert.New(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	gin.SetMode(gin.TestMode)
+	r := Router(ctx, logging.New(os.Stdout, logging.DebugLevel), cache.NewStore(10, 1*time.Minute))
+
+	tests := []struct {
+		name       string
+		url        string
+		accept     string
+		status     int
+		version    string
+		deprecated bool
+		response   string
+	}{
+		{
+			"version 1 path",
+			createQueryURL("/v1/calc/add", "1.5", "2"),
+			"",
+			http.StatusOK,
+			APIVersion1,
+			false,
+			`{"action":"add","x":1.5,"y":2,"answer":"3.5","cached":false}`,
+		},
+		{
+			"version 2 path",
+			createQueryURL("/v2/calc/add", "1.5", "2"),
+			"",
+			http.StatusOK,
+			APIVersion2,
+			false,
+			`{"action":"add","x":"1.5","y":"2","answer":"3.5","cached":true}`,
+		},
+		{
+			"path version wins over Accept header",
+			createQueryURL("/v1/calc/add", "1.5", "2"),
+			"application/vnd.teltech.v2+json",
+			http.StatusOK,
+			APIVersion1,
+			false,
+			`{"action":"add","x":1.5,"y":2,"answer":"3.5","cached":true}`,
+		},
+		{
+			"legacy route default version",
+			createQueryURL(AddEndpoint, "1.5", "2"),
+			"application/json",
+			http.StatusOK,
+			APIVersion1,
+			true,
+			`{"action":"add","x":1.5,"y":2,"answer":"3.5","cached":true}`,
+		},
+		{
+			"legacy route version from Accept header",
+			createQueryURL(AddEndpoint, "1.5", "2"),
+			"text/html, application/vnd.teltech.v2+json; q=0.9",
+			http.StatusOK,
+			APIVersion2,
+			true,
+			`{"action":"add","x":"1.5","y":"2","answer":"3.5","cached":true}`,
+		},
+		{
+			"legacy route unsupported version",
+			createQueryURL(AddEndpoint, "1.5", "2"),
+			"application/vnd.teltech.v9+json",
+			http.StatusNotAcceptable,
+			"",
+			true,
+			`{"error":"unsupported API version: v9"}`,
+		},
+	}
+
+	for _, test := range tests {
+		w := httptest.NewRecorder()
+		req, err := http.NewRequest(http.MethodGet, test.url, nil)
+		assert.NoError(err, "Error should be nil")
+
+		req.Header.Set(RequestIDHeader, "id")
+		if test.accept != "" {
+			req.Header.Set("Accept", test.accept)
+		}
+
+		r.ServeHTTP(w, req)
+		assert.Equal(test.status, w.Code, test.name)
+		assert.Equal(test.version, w.Header().Get(APIVersionHeader), test.name)
+
+		if test.status == http.StatusOK {
+			assert.Equal(test.response, w.Body.String(), test.name)
+		} else {
+			assert.JSONEq(test.response[:len(test.response)-1]+`,"request_id":"id"}`, w.Body.String(), test.name)
+		}
+
+		if test.deprecated {
+			assert.Equal("@1792368000", w.Header().Get(DeprecationHeader), test.name)
+			assert.Equal("Mon, 19 Apr 2027 00:00:00 GMT", w.Header().Get(SunsetHeader), test.name)
+			assert.Equal(`</v1/calc/add>; rel="successor-version"`, w.Header().Get(LinkHeader), test.name)
+		} else {
+			assert.Empty(w.Header().Get(DeprecationHeader), test.name)
+			assert.Empty(w.Header().Get(SunsetHeader), test.name)
+		}
+	}
+}
@@ -1,100 +1,85 @@
 package handler
 
 import (
-	"log"
 	"net/http"
 
 	"github.com/realmallaury/teltech/internal/arithmetic"
+	"github.com/realmallaury/teltech/internal/logging"
+	"github.com/realmallaury/teltech/internal/tracing"
 	"github.com/realmallaury/teltech/internal/utils"
 
 	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
 )
 
 // ArithmeticHandler holds data for handling basic math related requests.
 type ArithmeticHandler struct {
-	Logger *log.Logger
+	Logger *logging.Logger
+	Tracer *tracing.Tracer
 }
 
-// Add resource accepts two numbers and returns result in JSON response.
-func (ah *ArithmeticHandler) Add(c *gin.Context) {
-	x := c.Query("x")
-	y := c.Query("y")
-
-	if ok, err := utils.IsXYValid(x, y); !ok {
-		ah.Logger.Printf("Add method validation error: %v", err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+// Calculate resource accepts operation name in op path param and two numbers
+// from query params or request body and returns result in JSON response.
+func (ah *ArithmeticHandler) Calculate(c *gin.Context) {
+	action := c.Param("op")
+	if _, ok := arithmetic.Operations[action]; !ok {
+		errorResponse(c, http.StatusNotFound, errors.Errorf("unknown operation: %s", action))
 		return
 	}
 
-	result, err := arithmetic.Add(x, y)
-	if err != nil {
-		ah.Logger.Printf("Add method error: %v", err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
-	}
+	ah.calculate(c, action)
+}
 
-	c.JSON(http.StatusOK, result)
+// Add resource accepts two numbers from query params or request body and returns result in JSON response.
+func (ah *ArithmeticHandler) Add(c *gin.Context) {
+	ah.calculate(c, arithmetic.AddConst)
 }
 
-// Subtract resource accepts two numbers and returns result in JSON response.
+// Subtract resource accepts two numbers from query params or request body and returns result in JSON response.
 func (ah *ArithmeticHandler) Subtract(c *gin.Context) {
-	x := c.Query("x")
-	y := c.Query("y")
-
-	if ok, err := utils.IsXYValid(x, y); !ok {
-		ah.Logger.Printf("Add method validation error: %v", err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
-	}
-
-	result, err := arithmetic.Subtract(x, y)
-	if err != nil {
-		ah.Logger.Printf("subtract method error: %v", err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
-	}
-
-	c.JSON(http.StatusOK, result)
+	ah.calculate(c, arithmetic.SubtractConst)
 }
 
-// Multiply resource accepts two numbers and returns result in JSON response.
+// Multiply resource accepts two numbers from query params or request body and returns result in JSON response.
 func (ah *ArithmeticHandler) Multiply(c *gin.Context) {
-	x := c.Query("x")
-	y := c.Query("y")
+	ah.calculate(c, arithmetic.MultiplyConst)
+}
 
-	if ok, err := utils.IsXYValid(x, y); !ok {
-		ah.Logger.Printf("Add method validation error: %v", err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
-	}
+// Divide resource accepts two numbers from query params or request body and returns result in JSON response.
+func (ah *ArithmeticHandler) Divide(c *gin.Context) {
+	ah.calculate(c, arithmetic.DivideConst)
+}
 
-	result, err := arithmetic.Multiply(x, y)
+// Operations resource returns available operations with their arity and descriptions.
+func (ah *ArithmeticHandler) Operations(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"operations": arithmetic.OperationInfos})
+}
+
+func (ah *ArithmeticHandler) calculate(c *gin.Context, action string) {
+	x, y, err := operands(c)
 	if err != nil {
-		ah.Logger.Printf("multiply method error: %v", err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		requestLogger(c, ah.Logger).Warnf("%s operation request error: %v", action, err)
+		errorResponse(c, errorStatus(err), err)
 		return
 	}
 
-	c.JSON(http.StatusOK, result)
-}
-
-// Divide resource accepts two numbers and returns result in JSON response.
-func (ah *ArithmeticHandler) Divide(c *gin.Context) {
-	x := c.Query("x")
-	y := c.Query("y")
-
 	if ok, err := utils.IsXYValid(x, y); !ok {
-		ah.Logger.Printf("Add method validation error: %v", err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		requestLogger(c, ah.Logger).Warnf("%s operation validation error: %v", action, err)
+		errorResponse(c, http.StatusBadRequest, err)
 		return
 	}
 
-	result, err := arithmetic.Divide(x, y)
+	_, span := ah.Tracer.Start(c.Request.Context(), "arithmetic "+action)
+	result, err := arithmetic.Calculate(action, x, y)
+	span.SetError(err)
+	span.End()
+
 	if err != nil {
-		ah.Logger.Printf("divide method error: %v", err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		requestLogger(c, ah.Logger).Warnf("%s operation error: %v", action, err)
+		errorResponse(c, http.StatusBadRequest, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, result)
+	c.Set(resultKey, *result)
+	renderResult(c, *result)
 }
@@ -1,23 +1,27 @@
 package handler
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
-	"log"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
 	"os"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/realmallaury/teltech/internal/arithmetic"
+	"github.com/realmallaury/teltech/internal/cache"
+	"github.com/realmallaury/teltech/internal/logging"
 	"github.com/stretchr/testify/assert"
 )
 
 func getTestResources() (*gin.Engine, ArithmeticHandler) {
 	arithmeticHandler := ArithmeticHandler{
-		Logger: log.New(os.Stdout, "Test : ", log.LstdFlags|log.Lmicroseconds|log.Lshortfile),
+		Logger: logging.New(os.Stdout, logging.DebugLevel),
 	}
 
 	gin.SetMode(gin.TestMode)
@@ -173,6 +177,529 @@ func TestDivide(t *testing.T) {
 	)
 }
 
+func TestPost(t *testing.T) {
+	assert := assert.New(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	gin.SetMode(gin.TestMode)
+	r := Router(ctx, logging.New(os.Stdout, logging.DebugLevel), cache.NewStore(10, 1*time.Minute))
+
+	tests := []struct {
+		name        string
+		endpoint    string
+		contentType string
+		body        string
+		status      int
+		response    string
+	}{
+		{
+			"json body",
+			AddEndpoint,
+			"application/json",
+			`{"x": "1.5", "y": 2}`,
+			http.StatusOK,
+			`{"action":"add","x":1.5,"y":2,"answer":"3.5","cached":false}`,
+		},
+		{
+			"form body shares cache with json body",
+			AddEndpoint,
+			"application/x-www-form-urlencoded; charset=utf-8",
+			`y=2&x=1.5`,
+			http.StatusOK,
+			`{"action":"add","x":1.5,"y":2,"answer":"3.5","cached":true}`,
+		},
+		{
+			"validation error",
+			DivideEndpoint,
+			"application/json",
+			`{"x": "1--", "y": "1"}`,
+			http.StatusBadRequest,
+			`{"error":"x value: 1-- not valid number"}`,
+		},
+		{
+			"unknown json field",
+			MultiplyEndpoint,
+			"application/json",
+			`{"x": 1, "y": 2, "z": 3}`,
+			http.StatusBadRequest,
+			`{"error":"invalid request body: json: unknown field \"z\""}`,
+		},
+		{
+			"trailing json data",
+			MultiplyEndpoint,
+			"application/json",
+			`{"x": 1, "y": 2} {}`,
+			http.StatusBadRequest,
+			`{"error":"invalid request body: unexpected data after JSON object"}`,
+		},
+		{
+			"unknown form field",
+			SubtractEndpoint,
+			"application/x-www-form-urlencoded",
+			`x=1&y=2&z=3`,
+			http.StatusBadRequest,
+			`{"error":"invalid request body: unknown field \"z\""}`,
+		},
+		{
+			"unsupported content type",
+			SubtractEndpoint,
+			"text/plain",
+			`x=1&y=2`,
+			http.StatusUnsupportedMediaType,
+			`{"error":"unsupported content type: text/plain, expected application/json or application/x-www-form-urlencoded"}`,
+		},
+		{
+			"too large body",
+			SubtractEndpoint,
+			"application/json",
+			`{"x": "` + strings.Repeat("1", int(maxBodySize)) + `", "y": 1}`,
+			http.StatusRequestEntityTooLarge,
+			`{"error":"request body exceeds 1048576 bytes"}`,
+		},
+	}
+
+	for _, test := range tests {
+		w := httptest.NewRecorder()
+		req, err := http.NewRequest(http.MethodPost, test.endpoint, strings.NewReader(test.body))
+		assert.NoError(err, "Error should be nil")
+
+		req.Header.Set("Content-Type", test.contentType)
+
+		r.ServeHTTP(w, req)
+		assert.Equal(test.status, w.Code, test.name)
+
+		var body map[string]interface{}
+		assert.NoError(json.Unmarshal(w.Body.Bytes(), &body), test.name)
+		delete(body, requestIDKey)
+
+		response, _ := json.Marshal(body)
+		assert.JSONEq(test.response, string(response), test.name)
+	}
+
+	// Test that GET request shares cache with POST requests
+	w := httptest.NewRecorder()
+	req, err := http.NewRequest(http.MethodGet, AddEndpoint+"?y=2&x=1.5", nil)
+	assert.NoError(err, "Error should be nil")
+
+	r.ServeHTTP(w, req)
+	assert.Equal(`{"action":"add","x":1.5,"y":2,"answer":"3.5","cached":true}`, w.Body.String())
+}
+
+func TestLocale(t *testing.T) {
+	assert := assert.New(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	gin.SetMode(gin.TestMode)
+	r := Router(ctx, logging.New(os.Stdout, logging.DebugLevel), cache.NewStore(10, 1*time.Minute))
+
+	tests := []struct {
+		name        string
+		method      string
+		url         string
+		contentType string
+		body        string
+		status      int
+		response    string
+	}{
+		{
+			"query params in de locale",
+			http.MethodGet,
+			createQueryURL("/v1/calc/add", "1.000,25", "1,5") + "&locale=de",
+			"",
+			"",
+			http.StatusOK,
+			`{"action":"add","x":1000.25,"y":1.5,"answer":"1.001,75","cached":false}`,
+		},
+		{
+			"normalized operands share cache",
+			http.MethodGet,
+			createQueryURL("/v1/calc/add", "1000.25", "1.5"),
+			"",
+			"",
+			http.StatusOK,
+			`{"action":"add","x":1000.25,"y":1.5,"answer":"1001.75","cached":true}`,
+		},
+		{
+			"form body in en locale",
+			http.MethodPost,
+			"/v1/calc/multiply?locale=en",
+			"application/x-www-form-urlencoded",
+			"x=1%2C000&y=2",
+			http.StatusOK,
+			`{"action":"multiply","x":1000,"y":2,"answer":"2,000","cached":false}`,
+		},
+		{
+			"json string in locale and number without locale",
+			http.MethodPost,
+			"/v1/calc/subtract?locale=de-DE",
+			"application/json",
+			`{"x": "2,5", "y": 1.5}`,
+			http.StatusOK,
+			`{"action":"subtract","x":2.5,"y":1.5,"answer":"1","cached":false}`,
+		},
+		{
+			"decimal comma without locale",
+			http.MethodGet,
+			createQueryURL("/v1/calc/add", "1,5", "2"),
+			"",
+			"",
+			http.StatusBadRequest,
+			`{"error":"x value: 1,5 not valid number"}`,
+		},
+		{
+			"invalid grouping",
+			http.MethodPost,
+			"/v1/calc/add?locale=de",
+			"application/json",
+			`{"x": 1, "y": "1.00,5"}`,
+			http.StatusBadRequest,
+			`{"error":"y value: 1.00,5 not valid number"}`,
+		},
+	}
+
+	for _, test := range tests {
+		status, response := serveJSON(t, r, test.method, test.url, test.contentType, test.body)
+		assert.Equal(test.status, status, test.name)
+		assert.JSONEq(test.response, response, test.name)
+	}
+}
+
+func TestIntegerOperands(t *testing.T) {
+	assert := assert.New(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	gin.SetMode(gin.TestMode)
+	r := Router(ctx, logging.New(os.Stdout, logging.DebugLevel), cache.NewStore(10, 1*time.Minute))
+
+	tests := []struct {
+		name     string
+		method   string
+		url      string
+		body     string
+		status   int
+		response string
+	}{
+		{
+			"hexadecimal operand",
+			http.MethodGet,
+			createQueryURL("/v1/calc/add", "0xFF", "1"),
+			"",
+			http.StatusOK,
+			`{"action":"add","x":255,"y":1,"answer":"256","cached":false}`,
+		},
+		{
+			"hexadecimal answer",
+			http.MethodGet,
+			createQueryURL("/v1/calc/multiply", "0xffffffffffffffff", "0x10") + "&base=16",
+			"",
+			http.StatusOK,
+			`{"action":"multiply","x":18446744073709551615,"y":16,"answer":"0xffffffffffffffff0","cached":false}`,
+		},
+		{
+			"decimal operands with binary answer",
+			http.MethodGet,
+			createQueryURL("/v1/calc/add", "5", "3") + "&base=2",
+			"",
+			http.StatusOK,
+			`{"action":"add","x":5,"y":3,"answer":"0b1000","cached":false}`,
+		},
+		{
+			"integer operands don't share cache with float operands",
+			http.MethodGet,
+			createQueryURL("/v1/calc/add", "5", "3"),
+			"",
+			http.StatusOK,
+			`{"action":"add","x":5,"y":3,"answer":"8","cached":false}`,
+		},
+		{
+			"exact version 2 operands",
+			http.MethodGet,
+			createQueryURL("/v2/calc/multiply", "0xfffffffffffffffff", "0x2"),
+			"",
+			http.StatusOK,
+			`{"action":"multiply","x":"295147905179352825855","y":"2","answer":"590295810358705651710","cached":false}`,
+		},
+		{
+			"integer division",
+			http.MethodGet,
+			createQueryURL("/v1/calc/divide", "7", "2") + "&base=10",
+			"",
+			http.StatusOK,
+			`{"action":"divide","x":7,"y":2,"answer":"3","cached":false}`,
+		},
+		{
+			"arbitrary base answer",
+			http.MethodGet,
+			createQueryURL("/v1/calc/add", "0o43", "1") + "&base=36",
+			"",
+			http.StatusOK,
+			`{"action":"add","x":35,"y":1,"answer":"10","cached":false}`,
+		},
+		{
+			"json body with binary operand",
+			http.MethodPost,
+			"/v1/calc/subtract",
+			`{"x": "0b1010", "y": 15}`,
+			http.StatusOK,
+			`{"action":"subtract","x":10,"y":15,"answer":"-5","cached":false}`,
+		},
+		{
+			"decimal operand in locale format",
+			http.MethodGet,
+			createQueryURL("/v1/calc/add", "0xff", "1,000") + "&locale=en",
+			"",
+			http.StatusOK,
+			`{"action":"add","x":255,"y":1000,"answer":"1,255","cached":false}`,
+		},
+		{
+			"float operand with hexadecimal operand",
+			http.MethodGet,
+			createQueryURL("/v1/calc/add", "0xff", "1.5"),
+			"",
+			http.StatusBadRequest,
+			`{"error":"y value: 1.5 not valid integer, non-decimal operands and bases require integer operands"}`,
+		},
+		{
+			"float operand with base",
+			http.MethodGet,
+			createQueryURL("/v1/calc/add", "1.5", "2") + "&base=16",
+			"",
+			http.StatusBadRequest,
+			`{"error":"x value: 1.5 not valid integer, non-decimal operands and bases require integer operands"}`,
+		},
+		{
+			"integer operands with decimals",
+			http.MethodGet,
+			createQueryURL("/v1/calc/add", "0x1", "1") + "&decimals=2",
+			"",
+			http.StatusBadRequest,
+			`{"error":"notation and decimals can't be used with integer operands"}`,
+		},
+		{
+			"integer division by zero",
+			http.MethodGet,
+			createQueryURL("/v1/calc/divide", "0x1", "0"),
+			"",
+			http.StatusBadRequest,
+			`{"error":"integer division by zero"}`,
+		},
+	}
+
+	for _, test := range tests {
+		w := httptest.NewRecorder()
+		req, err := http.NewRequest(test.method, test.url, strings.NewReader(test.body))
+		assert.NoError(err, "Error should be nil")
+
+		if test.method == http.MethodPost {
+			req.Header.Set("Content-Type", "application/json")
+		}
+
+		r.ServeHTTP(w, req)
+		assert.Equal(test.status, w.Code, test.name)
+
+		var body map[string]interface{}
+		assert.NoError(json.Unmarshal(w.Body.Bytes(), &body), test.name)
+		delete(body, requestIDKey)
+
+		response, _ := json.Marshal(body)
+		assert.JSONEq(test.response, string(response), test.name)
+	}
+}
+
+func TestRationalOperands(t *testing.T) {
+	assert := assert.New(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	gin.SetMode(gin.TestMode)
+	r := Router(ctx, logging.New(os.Stdout, logging.DebugLevel), cache.NewStore(10, 1*time.Minute))
+
+	tests := []struct {
+		name     string
+		method   string
+		url      string
+		body     string
+		status   int
+		response string
+	}{
+		{
+			"fraction operands",
+			http.MethodGet,
+			createQueryURL("/v1/calc/add", "1/3", "1/6"),
+			"",
+			http.StatusOK,
+			`{"action":"add","x":0.3333333333333333,"y":0.16666666666666666,"answer":"1/2",` +
+				`"numerator":"1","denominator":"2","decimal":"0.5","cached":false}`,
+		},
+		{
+			"fraction with json number",
+			http.MethodPost,
+			"/v1/calc/add",
+			`{"x": "1/3", "y": 0.5}`,
+			http.StatusOK,
+			`{"action":"add","x":0.3333333333333333,"y":0.5,"answer":"5/6",` +
+				`"numerator":"5","denominator":"6","decimal":"0.8333333333333334","cached":false}`,
+		},
+		{
+			"decimal operand in locale format shares cache",
+			http.MethodGet,
+			createQueryURL("/v2/calc/add", "1/3", "0,5") + "&locale=de&decimals=3",
+			"",
+			http.StatusOK,
+			`{"action":"add","x":"1/3","y":"1/2","answer":"5/6",` +
+				`"numerator":"5","denominator":"6","decimal":"0,833","cached":true}`,
+		},
+		{
+			"rational division by zero",
+			http.MethodGet,
+			createQueryURL("/v1/calc/divide", "1/3", "0"),
+			"",
+			http.StatusBadRequest,
+			`{"error":"rational division by zero"}`,
+		},
+		{
+			"fraction with base",
+			http.MethodGet,
+			createQueryURL("/v1/calc/add", "1/3", "1") + "&base=16",
+			"",
+			http.StatusBadRequest,
+			`{"error":"x value: 1/3 not valid integer, non-decimal operands and bases require integer operands"}`,
+		},
+		{
+			"zero denominator",
+			http.MethodGet,
+			createQueryURL("/v1/calc/add", "1/0", "1"),
+			"",
+			http.StatusBadRequest,
+			`{"error":"x value: 1/0 not valid number"}`,
+		},
+	}
+
+	for _, test := range tests {
+		w := httptest.NewRecorder()
+		req, err := http.NewRequest(test.method, test.url, strings.NewReader(test.body))
+		assert.NoError(err, "Error should be nil")
+
+		if test.method == http.MethodPost {
+			req.Header.Set("Content-Type", "application/json")
+		}
+
+		r.ServeHTTP(w, req)
+		assert.Equal(test.status, w.Code, test.name)
+
+		var body map[string]interface{}
+		assert.NoError(json.Unmarshal(w.Body.Bytes(), &body), test.name)
+		delete(body, requestIDKey)
+
+		response, _ := json.Marshal(body)
+		assert.JSONEq(test.response, string(response), test.name)
+	}
+}
+
+func TestCalculate(t *testing.T) {
+	assert := assert.New(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	gin.SetMode(gin.TestMode)
+	r := Router(ctx, logging.New(os.Stdout, logging.DebugLevel), cache.NewStore(10, 1*time.Minute))
+
+	tests := []struct {
+		name     string
+		url      string
+		status   int
+		response string
+	}{
+		{
+			"versioned route",
+			createQueryURL("/v1/calc/multiply", "2", "3"),
+			http.StatusOK,
+			`{"action":"multiply","x":2,"y":3,"answer":"6","cached":false}`,
+		},
+		{
+			"legacy route shares cache with versioned route",
+			createQueryURL(MultiplyEndpoint, "2", "3"),
+			http.StatusOK,
+			`{"action":"multiply","x":2,"y":3,"answer":"6","cached":true}`,
+		},
+		{
+			"legacy route",
+			createQueryURL(SubtractEndpoint, "2", "3"),
+			http.StatusOK,
+			`{"action":"subtract","x":2,"y":3,"answer":"-1","cached":false}`,
+		},
+		{
+			"unknown operation",
+			createQueryURL("/v1/calc/modulo", "2", "3"),
+			http.StatusNotFound,
+			`{"error":"unknown operation: modulo"}`,
+		},
+		{
+			"validation error",
+			createQueryURL("/v1/calc/divide", "2", "3--"),
+			http.StatusBadRequest,
+			`{"error":"y value: 3-- not valid number"}`,
+		},
+	}
+
+	for _, test := range tests {
+		w := httptest.NewRecorder()
+		req, err := http.NewRequest(http.MethodGet, test.url, nil)
+		assert.NoError(err, "Error should be nil")
+
+		r.ServeHTTP(w, req)
+		assert.Equal(test.status, w.Code, test.name)
+
+		var body map[string]interface{}
+		assert.NoError(json.Unmarshal(w.Body.Bytes(), &body), test.name)
+		delete(body, requestIDKey)
+
+		response, _ := json.Marshal(body)
+		assert.JSONEq(test.response, string(response), test.name)
+	}
+
+	// Test that operations are listed
+	w := httptest.NewRecorder()
+	req, err := http.NewRequest(http.MethodGet, OperationsEndpoint, nil)
+	assert.NoError(err, "Error should be nil")
+
+	r.ServeHTTP(w, req)
+	assert.Equal(http.StatusOK, w.Code, "Response status should be OK")
+
+	var body struct {
+		Operations []arithmetic.OperationInfo `json:"operations"`
+	}
+	assert.NoError(json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(arithmetic.OperationInfos, body.Operations)
+}
+
+// serveJSON serves request with body of content type, empty content type is not set,
+// and returns response status and JSON body without request id.
+func serveJSON(t *testing.T, r http.Handler, method, url, contentType, body string) (int, string) {
+	req := httptest.NewRequest(method, url, strings.NewReader(body))
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	var response map[string]interface{}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response), "Response should be JSON: %s", w.Body.String())
+	delete(response, requestIDKey)
+
+	b, _ := json.Marshal(response)
+	return w.Code, string(b)
+}
+
 func createQueryURL(endpoint, x, y string) string {
 	params := url.Values{}
 	params.Add("x", x)
@@ -0,0 +1,231 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+	"github.com/realmallaury/teltech/internal/auth"
+)
+
+// Gin context keys.
+const (
+	identityKey string = "identity"
+	apiKeyKey   string = "api_key"
+)
+
+// bearerPrefix is Authorization header prefix of bearer tokens.
+const bearerPrefix string = "Bearer "
+
+// Authenticator validates API keys and bearer tokens, attaches caller identity
+// to request context and enforces API key quotas.
+type Authenticator struct {
+	keys  auth.KeyStore
+	usage auth.UsageStore
+
+	mux sync.RWMutex
+	// jwt validates bearer tokens, nil value disables bearer authentication.
+	jwt *auth.JWTValidator
+}
+
+// NewAuthenticator creates a new Authenticator instance.
+func NewAuthenticator(keys auth.KeyStore, usage auth.UsageStore, jwt *auth.JWTValidator) *Authenticator {
+	return &Authenticator{
+		keys:  keys,
+		usage: usage,
+		jwt:   jwt,
+	}
+}
+
+// SetJWTValidator replaces bearer token validator of the running authenticator,
+// nil validator disables bearer authentication.
+func (a *Authenticator) SetJWTValidator(jwt *auth.JWTValidator) {
+	a.mux.Lock()
+	defer a.mux.Unlock()
+
+	a.jwt = jwt
+}
+
+func (a *Authenticator) jwtValidator() *auth.JWTValidator {
+	a.mux.RLock()
+	defer a.mux.RUnlock()
+
+	return a.jwt
+}
+
+// Authenticate validates bearer token from Authorization header or API key from X-API-Key header
+// and attaches caller identity to request context.
+func (a *Authenticator) Authenticate(c *gin.Context) {
+	if a == nil {
+		return
+	}
+
+	header, apiKey := c.GetHeader("Authorization"), c.GetHeader(APIKeyHeader)
+
+	identity, key, status, err := a.Validate(header, apiKey)
+	if err != nil {
+		if status == http.StatusInternalServerError {
+			_ = c.Error(err)
+			err = errors.New("could not validate API key")
+		}
+
+		if status == http.StatusUnauthorized && a.jwtValidator() != nil {
+			if strings.HasPrefix(header, bearerPrefix) {
+				c.Header("WWW-Authenticate", `Bearer error="invalid_token"`)
+			} else if apiKey == "" {
+				c.Header("WWW-Authenticate", "Bearer")
+			}
+		}
+
+		errorResponse(c, status, err)
+		return
+	}
+
+	if key != nil {
+		c.Set(apiKeyKey, *key)
+	}
+
+	setIdentity(c, identity)
+}
+
+// Validate validates bearer token from authorization header value or API key and returns caller identity,
+// key is set when caller is authenticated with API key. Status is HTTP status code of failed validation.
+func (a *Authenticator) Validate(authorization, apiKey string) (auth.Identity, *auth.Key, int, error) {
+	if jwt := a.jwtValidator(); jwt != nil && strings.HasPrefix(authorization, bearerPrefix) {
+		identity, err := jwt.Validate(strings.TrimPrefix(authorization, bearerPrefix))
+		if err != nil {
+			return auth.Identity{}, nil, http.StatusUnauthorized, err
+		}
+
+		return identity, nil, http.StatusOK, nil
+	}
+
+	if apiKey == "" {
+		return auth.Identity{}, nil, http.StatusUnauthorized, errors.New("missing API key or bearer token")
+	}
+
+	key, ok, err := a.keys.Lookup(apiKey)
+	if err != nil {
+		return auth.Identity{}, nil, http.StatusInternalServerError, errors.Wrap(err, "key store lookup")
+	}
+
+	if !ok {
+		return auth.Identity{}, nil, http.StatusUnauthorized, errors.New("invalid API key")
+	}
+
+	return key.Identity(), &key, http.StatusOK, nil
+}
+
+// RequireScope rejects requests of callers which are not granted scope.
+func (a *Authenticator) RequireScope(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if a == nil {
+			return
+		}
+
+		identity, ok := getIdentity(c)
+		if !ok {
+			errorResponse(c, http.StatusUnauthorized, errors.New("missing credentials"))
+			return
+		}
+
+		if !identity.HasScope(scope) {
+			errorResponse(c, http.StatusForbidden, fmt.Errorf("missing scope: %s", scope))
+			return
+		}
+	}
+}
+
+// EnforceQuota counts API key request and rejects requests exceeding daily or monthly quota.
+func (a *Authenticator) EnforceQuota(c *gin.Context) {
+	if a == nil {
+		return
+	}
+
+	value, ok := c.Get(apiKeyKey)
+	if !ok {
+		return
+	}
+
+	allowed, err := a.ChargeQuota(value.(auth.Key))
+	if !allowed {
+		errorResponse(c, http.StatusTooManyRequests, err)
+		return
+	}
+
+	if err != nil {
+		// Usage store failures should not block clients, error is logged with request.
+		_ = c.Error(err)
+	}
+}
+
+// ChargeQuota counts API key request, returns false with error when daily or monthly quota is exceeded.
+// Usage store failures should not block clients, so they are returned as allowed with error.
+func (a *Authenticator) ChargeQuota(key auth.Key) (bool, error) {
+	if a == nil {
+		return true, nil
+	}
+
+	usage, allowed, err := a.usage.Increment(key, time.Now())
+	if err != nil {
+		return true, errors.Wrap(err, "usage store increment")
+	}
+
+	if !allowed {
+		period := "monthly"
+		if usage.DailyQuota > 0 && usage.DailyCount >= usage.DailyQuota {
+			period = "daily"
+		}
+
+		return false, fmt.Errorf("%s quota exceeded", period)
+	}
+
+	return true, nil
+}
+
+// Usage returns current quota usage of all API keys.
+func (a *Authenticator) Usage(c *gin.Context) {
+	keys, err := a.keys.List()
+	if err != nil {
+		_ = c.Error(errors.Wrap(err, "key store list"))
+		errorResponse(c, http.StatusInternalServerError, errors.New("could not list API keys"))
+		return
+	}
+
+	now := time.Now()
+	usage := make([]auth.Usage, 0, len(keys))
+
+	for _, key := range keys {
+		u, err := a.usage.Get(key, now)
+		if err != nil {
+			_ = c.Error(errors.Wrap(err, "usage store get"))
+			errorResponse(c, http.StatusInternalServerError, errors.New("could not get API key usage"))
+			return
+		}
+
+		usage = append(usage, u)
+	}
+
+	c.JSON(http.StatusOK, usage)
+}
+
+// setIdentity stores caller identity in gin and request context.
+func setIdentity(c *gin.Context, identity auth.Identity) {
+	c.Set(identityKey, identity)
+	c.Request = c.Request.WithContext(auth.ContextWithIdentity(c.Request.Context(), identity))
+}
+
+// getIdentity returns caller identity stored in gin context.
+func getIdentity(c *gin.Context) (auth.Identity, bool) {
+	value, ok := c.Get(identityKey)
+	if !ok {
+		return auth.Identity{}, false
+	}
+
+	identity, ok := value.(auth.Identity)
+	return identity, ok
+}
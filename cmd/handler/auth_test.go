@@ -0,0 +1,150 @@
+package handler
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/realmallaury/teltech/internal/auth"
+	"github.com/realmallaury/teltech/internal/cache"
+	"github.com/realmallaury/teltech/internal/logging"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuthenticator(t *testing.T) {
+	assert := assert.New(t)
+
+	keys := auth.NewMemoryKeyStore([]auth.Key{
+		{Key: "partner-key", Name: "partner", Scopes: []string{auth.ScopeArithmeticRead}, DailyQuota: 2},
+		{Key: "admin-key", Name: "admin", Scopes: []string{auth.ScopeAdminUsage}},
+	})
+	authenticator := NewAuthenticator(keys, auth.NewMemoryUsageStore(), nil)
+
+	gin.SetMode(gin.TestMode)
+	logger := logging.New(os.Stdout, logging.DebugLevel)
+	r := Router(context.Background(), logger, cache.NewStore(10, 1*time.Minute), WithAuthenticator(authenticator))
+
+	get := func(endpoint, apiKey string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, err := http.NewRequest(http.MethodGet, endpoint, nil)
+		assert.NoError(err, "Error should be nil")
+
+		if apiKey != "" {
+			req.Header.Set(APIKeyHeader, apiKey)
+		}
+
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	addURL := createQueryURL(AddEndpoint, "1", "1")
+
+	assert.Equal(http.StatusUnauthorized, get(addURL, "").Code, "Missing key should be rejected")
+	assert.Equal(http.StatusUnauthorized, get(addURL, "wrong").Code, "Invalid key should be rejected")
+	assert.Equal(http.StatusForbidden, get(addURL, "admin-key").Code, "Missing scope should be rejected")
+
+	// Test that daily quota is enforced
+	assert.Equal(http.StatusOK, get(addURL, "partner-key").Code, "Response status should be OK")
+	assert.Equal(http.StatusOK, get(addURL, "partner-key").Code, "Response status should be OK")
+
+	w := get(addURL, "partner-key")
+	assert.Equal(http.StatusTooManyRequests, w.Code, "Exceeded quota should be rejected")
+	assert.Contains(w.Body.String(), "daily quota exceeded")
+
+	// Test that usage is exposed to admin only
+	assert.Equal(http.StatusForbidden, get(UsageEndpoint, "partner-key").Code, "Missing scope should be rejected")
+
+	w = get(UsageEndpoint, "admin-key")
+	assert.Equal(http.StatusOK, w.Code, "Response status should be OK")
+
+	var usage []auth.Usage
+	_ = json.Unmarshal(w.Body.Bytes(), &usage)
+
+	assert.Len(usage, 2)
+	assert.Equal("partner", usage[0].Name)
+	assert.Equal(int64(2), usage[0].DailyCount)
+	assert.Equal(int64(2), usage[0].DailyQuota)
+	assert.Equal("admin", usage[1].Name)
+	assert.Equal(int64(0), usage[1].DailyCount)
+}
+
+func TestAuthenticatorJWT(t *testing.T) {
+	assert := assert.New(t)
+
+	hmacKey := []byte("secret")
+	authenticator := NewAuthenticator(
+		auth.NewMemoryKeyStore(nil),
+		auth.NewMemoryUsageStore(),
+		auth.NewJWTValidator(hmacKey, nil, "arithmetic"),
+	)
+
+	gin.SetMode(gin.TestMode)
+	logger := logging.New(os.Stdout, logging.DebugLevel)
+	r := Router(context.Background(), logger, cache.NewStore(10, 1*time.Minute), WithAuthenticator(authenticator))
+
+	token := func(scope string) string {
+		header, _ := json.Marshal(map[string]string{"alg": "HS256", "typ": "JWT"})
+		claims, _ := json.Marshal(map[string]interface{}{
+			"sub":   "gateway",
+			"aud":   "arithmetic",
+			"exp":   time.Now().Add(time.Hour).Unix(),
+			"scope": scope,
+		})
+
+		signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+		mac := hmac.New(sha256.New, hmacKey)
+		mac.Write([]byte(signingInput))
+
+		return signingInput + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+	}
+
+	request := func(method, endpoint, bearer string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, err := http.NewRequest(method, endpoint, nil)
+		assert.NoError(err, "Error should be nil")
+
+		if bearer != "" {
+			req.Header.Set("Authorization", "Bearer "+bearer)
+		}
+
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	addURL := createQueryURL(AddEndpoint, "1", "1")
+
+	w := request(http.MethodGet, addURL, "")
+	assert.Equal(http.StatusUnauthorized, w.Code, "Missing token should be rejected")
+	assert.Equal("Bearer", w.Header().Get("WWW-Authenticate"))
+
+	w = request(http.MethodGet, addURL, "invalid")
+	assert.Equal(http.StatusUnauthorized, w.Code, "Invalid token should be rejected")
+	assert.Equal(`Bearer error="invalid_token"`, w.Header().Get("WWW-Authenticate"))
+
+	assert.Equal(http.StatusOK, request(http.MethodGet, addURL, token(auth.ScopeArithmeticRead)).Code)
+	assert.Equal(http.StatusForbidden, request(http.MethodGet, addURL, token(auth.ScopeAdminCache)).Code)
+
+	// Test that cache admin routes require admin:cache scope
+	assert.Equal(http.StatusForbidden, request(http.MethodGet, CacheEndpoint, token(auth.ScopeArithmeticRead)).Code)
+
+	w = request(http.MethodGet, CacheEndpoint, token(auth.ScopeAdminCache))
+	assert.Equal(http.StatusOK, w.Code, "Response status should be OK")
+	assert.Contains(w.Body.String(), `"entries":1`)
+
+	assert.Equal(http.StatusNoContent, request(http.MethodDelete, CacheEndpoint, token(auth.ScopeAdminCache)).Code)
+
+	w = request(http.MethodGet, CacheEndpoint, token(auth.ScopeAdminCache))
+	assert.Contains(w.Body.String(), `"entries":0`)
+
+	// Test that tokens signed with old key are rejected after key rotation
+	authenticator.SetJWTValidator(auth.NewJWTValidator([]byte("rotated"), nil, "arithmetic"))
+	assert.Equal(http.StatusUnauthorized, request(http.MethodGet, addURL, token(auth.ScopeArithmeticRead)).Code)
+}
@@ -0,0 +1,178 @@
+package handler
+
+import (
+	"context"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+	"github.com/realmallaury/teltech/internal/arithmetic"
+	"github.com/realmallaury/teltech/internal/auth"
+	"github.com/realmallaury/teltech/internal/cache"
+	"github.com/realmallaury/teltech/internal/logging"
+	"github.com/realmallaury/teltech/internal/tracing"
+	"github.com/realmallaury/teltech/internal/utils"
+)
+
+const (
+	// maxBatchSize is maximum number of operations in batch request.
+	maxBatchSize int = 100
+
+	// maxBodySize is maximum size of request body in bytes.
+	maxBodySize int64 = 1 << 20
+)
+
+// Calculator validates operands and returns cached or computed arithmetic results,
+// used by APIs which don't go through cache middleware.
+type Calculator struct {
+	logger        *logging.Logger
+	store         cache.Store
+	tracer        *tracing.Tracer
+	events        *EventBus
+	rateLimiter   *RateLimiter
+	authenticator *Authenticator
+}
+
+// NewCalculator creates calculator sharing cache store with HTTP endpoints, tracer, event bus,
+// rate limiter and authenticator are set with the same options as Router.
+func NewCalculator(logger *logging.Logger, store cache.Store, opts ...Option) *Calculator {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return &Calculator{
+		logger:        logger,
+		store:         store,
+		tracer:        o.tracer,
+		events:        o.events,
+		rateLimiter:   o.rateLimiter,
+		authenticator: o.authenticator,
+	}
+}
+
+// Caller is client charged for operations calculated with its context.
+type Caller struct {
+	// Client identifies rate limit bucket of client, e.g. id:partner or ip:192.0.2.1.
+	Client string
+	// Key is API key charged for quota, nil when caller is not authenticated with API key.
+	Key *auth.Key
+}
+
+type callerContextKey struct{}
+
+// ContextWithCaller returns context which charges each operation of Calculator to caller
+// rate limit of the operation path, e.g. /multiply, and API key quota same as single HTTP request.
+func ContextWithCaller(ctx context.Context, caller Caller) context.Context {
+	return context.WithValue(ctx, callerContextKey{}, caller)
+}
+
+// withCaller charges operations calculated with request context to client rate limits and API key quota.
+func withCaller(c *gin.Context) {
+	caller := Caller{Client: rateLimitClient(c)}
+	if value, ok := c.Get(apiKeyKey); ok {
+		key := value.(auth.Key)
+		caller.Key = &key
+	}
+
+	c.Request = c.Request.WithContext(ContextWithCaller(c.Request.Context(), caller))
+}
+
+// limitError is error of operation rejected by rate limit or API key quota.
+type limitError struct {
+	error
+}
+
+// IsLimitError checks weather operation was rejected by rate limit or API key quota.
+func IsLimitError(err error) bool {
+	_, ok := errors.Cause(err).(limitError)
+	return ok
+}
+
+// Calculate returns result of arithmetic operation with action name.
+func (calc *Calculator) Calculate(ctx context.Context, action, x, y string) (*arithmetic.Result, error) {
+	start := time.Now()
+
+	if _, ok := arithmetic.Operations[action]; !ok {
+		return nil, errors.Errorf("unknown action: %s", action)
+	}
+
+	if err := calc.charge(ctx, action); err != nil {
+		return nil, err
+	}
+
+	if ok, err := utils.IsXYValid(x, y); !ok {
+		return nil, err
+	}
+
+	key := cache.OperationKey(action, x, y)
+
+	_, span := calc.tracer.Start(ctx, "cache lookup")
+	value, ok := calc.store.GetRecord(key)
+	span.SetAttribute("cache.hit", ok)
+	span.End()
+
+	if ok {
+		result := value.(arithmetic.Result)
+		result.Cached = true
+		calc.events.Publish(result, time.Since(start))
+		return &result, nil
+	}
+
+	_, span = calc.tracer.Start(ctx, "arithmetic "+action)
+	result, err := arithmetic.Calculate(action, x, y)
+	span.SetError(err)
+	span.End()
+
+	if err != nil {
+		return nil, err
+	}
+
+	calc.store.StoreRecord(key, *result)
+	calc.events.Publish(*result, time.Since(start))
+
+	return result, nil
+}
+
+// Evaluate returns answer of arithmetic expression, each operation of expression is cached
+// same as single operations.
+func (calc *Calculator) Evaluate(ctx context.Context, expression string) (*arithmetic.ExpressionResult, error) {
+	return arithmetic.Evaluate(expression, func(action, x, y string) (*arithmetic.Result, error) {
+		return calc.Calculate(ctx, action, x, y)
+	})
+}
+
+// charge takes rate limit token of caller for operation path and counts API key quota,
+// operations calculated without caller in context are not charged.
+func (calc *Calculator) charge(ctx context.Context, action string) error {
+	caller, ok := ctx.Value(callerContextKey{}).(Caller)
+	if !ok {
+		return nil
+	}
+
+	result, err := calc.rateLimiter.Take(caller.Client, "/"+action)
+	if err != nil {
+		// Backend failures should not block clients.
+		calc.logger.Errorf("Rate limiter error: %v", err)
+	}
+
+	if !result.Allowed {
+		return limitError{RateLimitError(result)}
+	}
+
+	if caller.Key == nil {
+		return nil
+	}
+
+	allowed, err := calc.authenticator.ChargeQuota(*caller.Key)
+	if !allowed {
+		return limitError{err}
+	}
+
+	if err != nil {
+		// Usage store failures should not block clients.
+		calc.logger.Errorf("Quota error: %v", err)
+	}
+
+	return nil
+}
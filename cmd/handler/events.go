@@ -0,0 +1,181 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+	"github.com/realmallaury/teltech/internal/arithmetic"
+)
+
+const (
+	// eventBufferSize is number of events buffered per subscriber,
+	// events are dropped for subscribers which don't keep up.
+	eventBufferSize int = 64
+
+	// maxEventSubscribers is default maximum number of concurrent event subscribers.
+	maxEventSubscribers int = 100
+
+	// eventKeepAlivePeriod is period of comments sent to keep idle streams open.
+	eventKeepAlivePeriod = 15 * time.Second
+
+	// resultEventName is SSE event name of computed results.
+	resultEventName string = "result"
+)
+
+// ResultEvent is published for each computed or cached arithmetic result.
+type ResultEvent struct {
+	arithmetic.Result
+	LatencyMS float64 `json:"latency_ms"`
+}
+
+// ErrTooManySubscribers is returned when maximum number of event subscribers is reached.
+var ErrTooManySubscribers = errors.New("too many event subscribers")
+
+// EventBus publishes result events to subscribers.
+type EventBus struct {
+	mux            sync.RWMutex
+	subscribers    map[*Subscription]struct{}
+	maxSubscribers int
+}
+
+// Subscription receives published events matching its action filter.
+type Subscription struct {
+	C <-chan ResultEvent
+
+	events  chan ResultEvent
+	actions map[string]bool
+}
+
+// NewEventBus creates event bus without subscribers.
+func NewEventBus() *EventBus {
+	return &EventBus{
+		subscribers:    map[*Subscription]struct{}{},
+		maxSubscribers: maxEventSubscribers,
+	}
+}
+
+// Subscribe returns subscription to events with given actions, all events are received when actions are empty.
+// ErrTooManySubscribers is returned when maximum number of subscribers is reached.
+func (eb *EventBus) Subscribe(actions ...string) (*Subscription, error) {
+	events := make(chan ResultEvent, eventBufferSize)
+
+	sub := &Subscription{
+		C:       events,
+		events:  events,
+		actions: map[string]bool{},
+	}
+
+	for _, action := range actions {
+		sub.actions[action] = true
+	}
+
+	eb.mux.Lock()
+	defer eb.mux.Unlock()
+
+	if len(eb.subscribers) >= eb.maxSubscribers {
+		return nil, ErrTooManySubscribers
+	}
+
+	eb.subscribers[sub] = struct{}{}
+
+	return sub, nil
+}
+
+// Unsubscribe removes subscription and closes its channel.
+func (eb *EventBus) Unsubscribe(sub *Subscription) {
+	eb.mux.Lock()
+	defer eb.mux.Unlock()
+
+	if _, ok := eb.subscribers[sub]; ok {
+		delete(eb.subscribers, sub)
+		close(sub.events)
+	}
+}
+
+// Publish sends event to matching subscribers without blocking, it is no-op for nil bus.
+func (eb *EventBus) Publish(result arithmetic.Result, latency time.Duration) {
+	if eb == nil {
+		return
+	}
+
+	event := ResultEvent{
+		Result:    result,
+		LatencyMS: float64(latency.Microseconds()) / 1000,
+	}
+
+	eb.mux.RLock()
+	defer eb.mux.RUnlock()
+
+	for sub := range eb.subscribers {
+		if len(sub.actions) > 0 && !sub.actions[event.Action] {
+			continue
+		}
+
+		select {
+		case sub.events <- event:
+		default:
+		}
+	}
+}
+
+// EventsHandler streams published results to clients, streams are closed when ctx is canceled.
+type EventsHandler struct {
+	ctx context.Context
+	bus *EventBus
+}
+
+// Stream sends published results as server-sent events until client disconnects,
+// results can be filtered with action query param, e.g. ?action=add,divide.
+func (eh *EventsHandler) Stream(c *gin.Context) {
+	var actions []string
+	for _, param := range c.QueryArray("action") {
+		for _, action := range strings.Split(param, ",") {
+			if _, ok := arithmetic.Operations[action]; !ok {
+				errorResponse(c, http.StatusBadRequest, errors.Errorf("unknown action: %s", action))
+				return
+			}
+
+			actions = append(actions, action)
+		}
+	}
+
+	sub, err := eh.bus.Subscribe(actions...)
+	if err != nil {
+		errorResponse(c, http.StatusServiceUnavailable, err)
+		return
+	}
+	defer eh.bus.Unsubscribe(sub)
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	c.Writer.Flush()
+
+	ticker := time.NewTicker(eventKeepAlivePeriod)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case event := <-sub.C:
+			c.SSEvent(resultEventName, event)
+			c.Writer.Flush()
+
+		case <-ticker.C:
+			_, _ = c.Writer.WriteString(":keepalive\n\n")
+			c.Writer.Flush()
+
+		case <-c.Request.Context().Done():
+			return
+
+		case <-eh.ctx.Done():
+			return
+		}
+	}
+}
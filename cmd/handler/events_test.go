@@ -0,0 +1,179 @@
+package handler
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/realmallaury/teltech/internal/arithmetic"
+	"github.com/realmallaury/teltech/internal/auth"
+	"github.com/realmallaury/teltech/internal/cache"
+	"github.com/realmallaury/teltech/internal/logging"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventBus(t *testing.T) {
+	assert := assert.New(t)
+
+	bus := NewEventBus()
+
+	all, err := bus.Subscribe()
+	assert.NoError(err, "Error should be nil")
+	divide, err := bus.Subscribe(arithmetic.DivideConst)
+	assert.NoError(err, "Error should be nil")
+
+	bus.Publish(arithmetic.Result{Action: arithmetic.AddConst, Answer: "2"}, 1500*time.Microsecond)
+	bus.Publish(arithmetic.Result{Action: arithmetic.DivideConst, Answer: "1"}, time.Millisecond)
+
+	assert.Equal(ResultEvent{Result: arithmetic.Result{Action: arithmetic.AddConst, Answer: "2"}, LatencyMS: 1.5}, <-all.C)
+	assert.Equal(arithmetic.DivideConst, (<-all.C).Action)
+	assert.Equal(arithmetic.DivideConst, (<-divide.C).Action)
+	assert.Len(divide.C, 0, "Filtered events should not be received")
+
+	// Test that events are dropped for slow subscribers
+	for i := 0; i < eventBufferSize+1; i++ {
+		bus.Publish(arithmetic.Result{Action: arithmetic.AddConst}, 0)
+	}
+	assert.Len(all.C, eventBufferSize)
+
+	// Test that unsubscribed channel is closed
+	bus.Unsubscribe(divide)
+	_, ok := <-divide.C
+	assert.False(ok, "Channel should be closed")
+
+	// Test that number of subscribers is limited
+	bus.maxSubscribers = 2
+	_, err = bus.Subscribe()
+	assert.NoError(err, "Error should be nil")
+	_, err = bus.Subscribe()
+	assert.Equal(ErrTooManySubscribers, err)
+
+	// Test that publishing to nil bus is no-op
+	var nilBus *EventBus
+	nilBus.Publish(arithmetic.Result{}, 0)
+}
+
+func TestEventsStream(t *testing.T) {
+	assert := assert.New(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	gin.SetMode(gin.TestMode)
+	r := Router(ctx, logging.New(os.Stdout, logging.DebugLevel), cache.NewStore(10, 1*time.Minute))
+
+	server := httptest.NewServer(r)
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + EventsEndpoint + "?action=multiply,divide")
+	assert.NoError(err, "Error should be nil")
+	defer resp.Body.Close()
+
+	assert.Equal(http.StatusOK, resp.StatusCode)
+	assert.Equal("text/event-stream", resp.Header.Get("Content-Type"))
+
+	for _, endpoint := range []string{AddEndpoint, MultiplyEndpoint, MultiplyEndpoint} {
+		resp, err := http.Get(server.URL + createQueryURL(endpoint, "2", "3"))
+		assert.NoError(err, "Error should be nil")
+		resp.Body.Close()
+	}
+
+	reader := bufio.NewReader(resp.Body)
+
+	var events []ResultEvent
+	for len(events) < 2 {
+		line, err := reader.ReadString('\n')
+		if !assert.NoError(err, "Error should be nil") {
+			return
+		}
+
+		if strings.HasPrefix(line, "event:") {
+			assert.Equal("event:"+resultEventName+"\n", line)
+		}
+
+		if strings.HasPrefix(line, "data:") {
+			var event ResultEvent
+			assert.NoError(json.Unmarshal([]byte(strings.TrimPrefix(line, "data:")), &event))
+			events = append(events, event)
+		}
+	}
+
+	assert.Equal(arithmetic.MultiplyConst, events[0].Action)
+	assert.Equal("6", events[0].Answer)
+	assert.False(events[0].Cached)
+	assert.True(events[1].Cached)
+
+	// Test that stream is closed on shutdown
+	cancel()
+
+	rest, err := ioutil.ReadAll(reader)
+	assert.NoError(err, "Stream should be closed")
+	assert.Equal("\n", string(rest))
+
+	// Test that unknown action filter is rejected
+	resp, err = http.Get(server.URL + EventsEndpoint + "?action=modulo")
+	assert.NoError(err, "Error should be nil")
+	resp.Body.Close()
+	assert.Equal(http.StatusBadRequest, resp.StatusCode)
+}
+
+func TestEventsScope(t *testing.T) {
+	assert := assert.New(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	authenticator := NewAuthenticator(
+		auth.NewMemoryKeyStore([]auth.Key{
+			{Key: "reader", Name: "reader", Scopes: []string{auth.ScopeArithmeticRead}},
+			{Key: "events", Name: "events", Scopes: []string{auth.ScopeAdminEvents}},
+		}),
+		auth.NewMemoryUsageStore(),
+		nil,
+	)
+
+	events := NewEventBus()
+	events.maxSubscribers = 0
+
+	gin.SetMode(gin.TestMode)
+	r := Router(
+		ctx,
+		logging.New(os.Stdout, logging.DebugLevel),
+		cache.NewStore(10, 1*time.Minute),
+		WithAuthenticator(authenticator),
+		WithEventBus(events),
+	)
+
+	tests := []struct {
+		apiKey string
+		status int
+		err    string
+	}{
+		{"", http.StatusUnauthorized, "missing API key or bearer token"},
+		{"reader", http.StatusForbidden, "missing scope: admin:events"},
+		{"events", http.StatusServiceUnavailable, "too many event subscribers"},
+	}
+
+	for _, test := range tests {
+		w := httptest.NewRecorder()
+		req, err := http.NewRequest(http.MethodGet, EventsEndpoint, nil)
+		assert.NoError(err, "Error should be nil")
+
+		req.Header.Set(RequestIDHeader, "id")
+		if test.apiKey != "" {
+			req.Header.Set(APIKeyHeader, test.apiKey)
+		}
+
+		r.ServeHTTP(w, req)
+		assert.Equal(test.status, w.Code, test.apiKey)
+		assert.JSONEq(`{"error":"`+test.err+`","request_id":"id"}`, w.Body.String(), test.apiKey)
+	}
+}
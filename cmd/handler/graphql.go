@@ -0,0 +1,450 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/graphql-go/graphql/language/source"
+	"github.com/pkg/errors"
+	"github.com/realmallaury/teltech/internal/arithmetic"
+	"github.com/realmallaury/teltech/internal/logging"
+	"github.com/realmallaury/teltech/internal/utils"
+)
+
+// GraphQL query limits.
+const (
+	// maxGraphQLFields is maximum number of root fields of query, including aliases and fields of fragments.
+	maxGraphQLFields int = 100
+	// maxGraphQLOperations is maximum number of arithmetic operations of request, queries of batch request
+	// share the limit.
+	maxGraphQLOperations int = 1000
+)
+
+// GraphQLHandler serves arithmetic operations over GraphQL.
+type GraphQLHandler struct {
+	logger     *logging.Logger
+	calculator *Calculator
+	schema     graphql.Schema
+}
+
+type graphQLRequest struct {
+	Query         string                 `json:"query"`
+	Variables     map[string]interface{} `json:"variables"`
+	OperationName string                 `json:"operationName"`
+}
+
+type graphQLBatchResult struct {
+	Result *arithmetic.Result `json:"result"`
+	Error  *string            `json:"error"`
+}
+
+// numberScalar accepts operands as GraphQL int, float or string values and passes them on as strings
+// so they are validated the same way as query params of HTTP endpoints.
+var numberScalar = graphql.NewScalar(graphql.ScalarConfig{
+	Name:        "Number",
+	Description: "Integer or float operand, can be passed as number or string.",
+	Serialize: func(value interface{}) interface{} {
+		return value
+	},
+	ParseValue: func(value interface{}) interface{} {
+		switch v := value.(type) {
+		case string:
+			return v
+		case float64:
+			return utils.FloatToString(v)
+		case int:
+			return utils.FloatToString(float64(v))
+		}
+
+		return nil
+	},
+	ParseLiteral: func(value ast.Value) interface{} {
+		switch v := value.(type) {
+		case *ast.IntValue:
+			return v.Value
+		case *ast.FloatValue:
+			return v.Value
+		case *ast.StringValue:
+			return v.Value
+		}
+
+		return nil
+	},
+})
+
+// NewGraphQLHandler creates GraphQL handler with schema for single operations, batches and expressions,
+// it panics if schema is not valid.
+func NewGraphQLHandler(logger *logging.Logger, calculator *Calculator) *GraphQLHandler {
+	gh := &GraphQLHandler{
+		logger:     logger,
+		calculator: calculator,
+	}
+
+	resultType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Result",
+		Description: "Result of arithmetic operation.",
+		Fields: graphql.Fields{
+			"action": &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"x":      &graphql.Field{Type: graphql.NewNonNull(graphql.Float)},
+			"y":      &graphql.Field{Type: graphql.NewNonNull(graphql.Float)},
+			"answer": &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"numerator": &graphql.Field{
+				Type:        graphql.String,
+				Description: "Numerator of rational answer, set when operand is fraction.",
+			},
+			"denominator": &graphql.Field{
+				Type:        graphql.String,
+				Description: "Denominator of rational answer, set when operand is fraction.",
+			},
+			"decimal": &graphql.Field{
+				Type:        graphql.String,
+				Description: "Decimal approximation of rational answer, set when operand is fraction.",
+			},
+			"cached": &graphql.Field{Type: graphql.NewNonNull(graphql.Boolean)},
+		},
+	})
+
+	batchResultType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "BatchResult",
+		Description: "Result or error of single operation in batch.",
+		Fields: graphql.Fields{
+			"result": &graphql.Field{Type: resultType},
+			"error":  &graphql.Field{Type: graphql.String},
+		},
+	})
+
+	expressionResultType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "ExpressionResult",
+		Description: "Answer of arithmetic expression.",
+		Fields: graphql.Fields{
+			"expression": &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"answer":     &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"cached": &graphql.Field{
+				Type:        graphql.NewNonNull(graphql.Boolean),
+				Description: "True when all operations of expression were served from cache.",
+			},
+		},
+	})
+
+	operationInput := graphql.NewInputObject(graphql.InputObjectConfig{
+		Name: "OperationInput",
+		Fields: graphql.InputObjectConfigFieldMap{
+			"action": &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.String)},
+			"x":      &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(numberScalar)},
+			"y":      &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(numberScalar)},
+		},
+	})
+
+	queryType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"calculate": &graphql.Field{
+				Type:        resultType,
+				Description: "Returns result of add, subtract, multiply or divide action.",
+				Args: graphql.FieldConfigArgument{
+					"action": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+					"x":      &graphql.ArgumentConfig{Type: graphql.NewNonNull(numberScalar)},
+					"y":      &graphql.ArgumentConfig{Type: graphql.NewNonNull(numberScalar)},
+				},
+				Resolve: gh.resolveCalculate,
+			},
+			"batch": &graphql.Field{
+				Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(batchResultType))),
+				Description: "Returns results of multiple operations, failed operations have error set.",
+				Args: graphql.FieldConfigArgument{
+					"operations": &graphql.ArgumentConfig{
+						Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(operationInput))),
+					},
+				},
+				Resolve: gh.resolveBatch,
+			},
+			"evaluate": &graphql.Field{
+				Type:        expressionResultType,
+				Description: "Returns answer of expression with +, -, *, / operators and parentheses, e.g. (1.5 + 0x10) * -2.",
+				Args: graphql.FieldConfigArgument{
+					"expression": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+				},
+				Resolve: gh.resolveEvaluate,
+			},
+		},
+	})
+
+	schema, err := graphql.NewSchema(graphql.SchemaConfig{Query: queryType})
+	if err != nil {
+		panic(errors.Wrap(err, "creating GraphQL schema"))
+	}
+
+	gh.schema = schema
+	return gh
+}
+
+// Serve executes GraphQL query from GET query params or POST JSON body,
+// POST body can be array of queries executed as batch.
+func (gh *GraphQLHandler) Serve(c *gin.Context) {
+	if c.Request.Method == http.MethodGet {
+		req := graphQLRequest{
+			Query:         c.Query("query"),
+			OperationName: c.Query("operationName"),
+		}
+
+		if variables := c.Query("variables"); variables != "" {
+			if err := json.Unmarshal([]byte(variables), &req.Variables); err != nil {
+				errorResponse(c, http.StatusBadRequest, errors.Wrap(err, "invalid variables"))
+				return
+			}
+		}
+
+		operations := maxGraphQLOperations
+		c.JSON(http.StatusOK, gh.execute(c, req, &operations))
+		return
+	}
+
+	body, err := ioutil.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxBodySize))
+	if err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	body = bytes.TrimSpace(body)
+	if len(body) > 0 && body[0] == '[' {
+		var batch []graphQLRequest
+		if err := json.Unmarshal(body, &batch); err != nil {
+			errorResponse(c, http.StatusBadRequest, errors.Wrap(err, "invalid request body"))
+			return
+		}
+
+		if len(batch) > maxBatchSize {
+			errorResponse(c, http.StatusBadRequest, errors.Errorf("batch size: %d exceeds maximum: %d", len(batch), maxBatchSize))
+			return
+		}
+
+		operations := maxGraphQLOperations
+		results := make([]*graphql.Result, 0, len(batch))
+		for _, req := range batch {
+			results = append(results, gh.execute(c, req, &operations))
+		}
+
+		c.JSON(http.StatusOK, results)
+		return
+	}
+
+	var req graphQLRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		errorResponse(c, http.StatusBadRequest, errors.Wrap(err, "invalid request body"))
+		return
+	}
+
+	operations := maxGraphQLOperations
+	c.JSON(http.StatusOK, gh.execute(c, req, &operations))
+}
+
+func (gh *GraphQLHandler) execute(c *gin.Context, req graphQLRequest, operations *int) *graphql.Result {
+	result := gh.run(c.Request.Context(), req, operations)
+
+	for _, err := range result.Errors {
+		requestLogger(c, gh.logger).Warnf("GraphQL query error: %v", err.Message)
+	}
+
+	return result
+}
+
+// run validates and executes query, queries exceeding field limit or remaining operations of request
+// are rejected before execution.
+func (gh *GraphQLHandler) run(ctx context.Context, req graphQLRequest, operations *int) *graphql.Result {
+	doc, err := parser.Parse(parser.ParseParams{
+		Source: source.NewSource(&source.Source{Body: []byte(req.Query), Name: "GraphQL request"}),
+	})
+	if err != nil {
+		return &graphql.Result{Errors: gqlerrors.FormatErrors(err)}
+	}
+
+	if validation := graphql.ValidateDocument(&gh.schema, doc, nil); !validation.IsValid {
+		return &graphql.Result{Errors: validation.Errors}
+	}
+
+	cost := queryCost{doc: doc, variables: req.Variables}
+	cost.operation(req.OperationName)
+
+	if cost.fields > maxGraphQLFields {
+		err := errors.Errorf("query exceeds maximum of %d fields", maxGraphQLFields)
+		return &graphql.Result{Errors: gqlerrors.FormatErrors(err)}
+	}
+
+	*operations -= cost.operations
+	if *operations < 0 {
+		err := errors.Errorf("request exceeds maximum of %d operations", maxGraphQLOperations)
+		return &graphql.Result{Errors: gqlerrors.FormatErrors(err)}
+	}
+
+	return graphql.Execute(graphql.ExecuteParams{
+		Schema:        gh.schema,
+		AST:           doc,
+		OperationName: req.OperationName,
+		Args:          req.Variables,
+		Context:       ctx,
+	})
+}
+
+func (gh *GraphQLHandler) resolveCalculate(p graphql.ResolveParams) (interface{}, error) {
+	action, _ := p.Args["action"].(string)
+	x, _ := p.Args["x"].(string)
+	y, _ := p.Args["y"].(string)
+
+	return gh.calculator.Calculate(p.Context, action, x, y)
+}
+
+func (gh *GraphQLHandler) resolveBatch(p graphql.ResolveParams) (interface{}, error) {
+	operations, _ := p.Args["operations"].([]interface{})
+	if len(operations) > maxBatchSize {
+		return nil, errors.Errorf("batch size: %d exceeds maximum: %d", len(operations), maxBatchSize)
+	}
+
+	results := make([]graphQLBatchResult, 0, len(operations))
+	for _, operation := range operations {
+		args, _ := operation.(map[string]interface{})
+		action, _ := args["action"].(string)
+		x, _ := args["x"].(string)
+		y, _ := args["y"].(string)
+
+		result, err := gh.calculator.Calculate(p.Context, action, x, y)
+		if err != nil {
+			message := err.Error()
+			results = append(results, graphQLBatchResult{Error: &message})
+			continue
+		}
+
+		results = append(results, graphQLBatchResult{Result: result})
+	}
+
+	return results, nil
+}
+
+func (gh *GraphQLHandler) resolveEvaluate(p graphql.ResolveParams) (interface{}, error) {
+	expression, _ := p.Args["expression"].(string)
+
+	return gh.calculator.Evaluate(p.Context, expression)
+}
+
+// queryCost counts root fields and upper bound of arithmetic operations of query,
+// counting stops when fields exceed maxGraphQLFields.
+type queryCost struct {
+	doc        *ast.Document
+	variables  map[string]interface{}
+	fields     int
+	operations int
+}
+
+// operation counts fields of operation with name, or the only operation of document when name is empty.
+func (qc *queryCost) operation(name string) {
+	for _, definition := range qc.doc.Definitions {
+		operation, ok := definition.(*ast.OperationDefinition)
+		if !ok {
+			continue
+		}
+
+		if name == "" || operation.Name != nil && operation.Name.Value == name {
+			qc.selectionSet(operation.SelectionSet)
+			return
+		}
+	}
+}
+
+func (qc *queryCost) selectionSet(set *ast.SelectionSet) {
+	if set == nil {
+		return
+	}
+
+	for _, selection := range set.Selections {
+		if qc.fields > maxGraphQLFields {
+			return
+		}
+
+		qc.fields++
+
+		switch s := selection.(type) {
+		case *ast.Field:
+			qc.operations += qc.fieldOperations(s)
+		case *ast.InlineFragment:
+			qc.selectionSet(s.SelectionSet)
+		case *ast.FragmentSpread:
+			qc.selectionSet(qc.fragment(s.Name.Value))
+		}
+	}
+}
+
+func (qc *queryCost) fragment(name string) *ast.SelectionSet {
+	for _, definition := range qc.doc.Definitions {
+		if fragment, ok := definition.(*ast.FragmentDefinition); ok && fragment.Name.Value == name {
+			return fragment.SelectionSet
+		}
+	}
+
+	return nil
+}
+
+// fieldOperations returns number of arithmetic operations of root field, operations of expression
+// are counted by its operators.
+func (qc *queryCost) fieldOperations(field *ast.Field) int {
+	switch field.Name.Value {
+	case "calculate":
+		return 1
+
+	case "batch":
+		switch v := qc.argument(field, "operations").(type) {
+		case *ast.ListValue:
+			return len(v.Values)
+		case []interface{}:
+			return len(v)
+		}
+
+		return 1
+
+	case "evaluate":
+		var expression string
+		switch v := qc.argument(field, "expression").(type) {
+		case *ast.StringValue:
+			expression = v.Value
+		case string:
+			expression = v
+		}
+
+		operations := 0
+		for _, operator := range []string{"+", "-", "*", "/"} {
+			operations += strings.Count(expression, operator)
+		}
+
+		if operations > arithmetic.MaxExpressionOperations {
+			return arithmetic.MaxExpressionOperations
+		}
+
+		return operations
+	}
+
+	return 0
+}
+
+// argument returns AST value of field argument or value of variable passed as argument.
+func (qc *queryCost) argument(field *ast.Field, name string) interface{} {
+	for _, argument := range field.Arguments {
+		if argument.Name.Value != name {
+			continue
+		}
+
+		if variable, ok := argument.Value.(*ast.Variable); ok {
+			return qc.variables[variable.Name.Value]
+		}
+
+		return argument.Value
+	}
+
+	return nil
+}
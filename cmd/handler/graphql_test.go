@@ -0,0 +1,215 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/realmallaury/teltech/internal/arithmetic"
+	"github.com/realmallaury/teltech/internal/auth"
+	"github.com/realmallaury/teltech/internal/cache"
+	"github.com/realmallaury/teltech/internal/logging"
+	"github.com/realmallaury/teltech/internal/ratelimit"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGraphQL(t *testing.T) {
+	assert := assert.New(t)
+
+	graphQLHandler := NewGraphQLHandler(
+		logging.New(os.Stdout, logging.DebugLevel),
+		NewCalculator(logging.New(os.Stdout, logging.DebugLevel), cache.NewStore(10, 1*time.Minute)),
+	)
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET(GraphQLEndpoint, graphQLHandler.Serve)
+	r.POST(GraphQLEndpoint, graphQLHandler.Serve)
+
+	aliases := `{"query":"{` + strings.Repeat(` a: calculate(action: \"add\", x: 1, y: 2) { answer }`, maxGraphQLFields+1) + ` }"}`
+
+	expression := `{"query":"{ evaluate(expression: \"1` + strings.Repeat(` + 1`, arithmetic.MaxExpressionOperations) + `\") { answer } }"}`
+	expressions := "[" + strings.Repeat(expression+",", maxGraphQLOperations/arithmetic.MaxExpressionOperations) + expression + "]"
+
+	tests := []struct {
+		name   string
+		method string
+		body   string
+		status int
+		result string
+	}{
+		{
+			"single operation",
+			http.MethodPost,
+			`{"query":"{ calculate(action: \"add\", x: 1, y: \"2.5\") { answer cached } }"}`,
+			http.StatusOK,
+			`{"data":{"calculate":{"answer":"3.5","cached":false}}}`,
+		},
+		{
+			"cached flag and variables",
+			http.MethodPost,
+			`{"query":"query Add($x: Number!) { calculate(action: \"add\", x: $x, y: 2.5) { action x y answer cached } }","variables":{"x":1}}`,
+			http.StatusOK,
+			`{"data":{"calculate":{"action":"add","answer":"3.5","cached":true,"x":1,"y":2.5}}}`,
+		},
+		{
+			"rational operation",
+			http.MethodPost,
+			`{"query":"{ calculate(action: \"add\", x: \"1/3\", y: \"1/6\") { answer numerator denominator decimal } }"}`,
+			http.StatusOK,
+			`{"data":{"calculate":{"answer":"1/2","decimal":"0.5","denominator":"2","numerator":"1"}}}`,
+		},
+		{
+			"validation error",
+			http.MethodPost,
+			`{"query":"{ calculate(action: \"divide\", x: \"1--\", y: 2) { answer } }"}`,
+			http.StatusOK,
+			`{"data":{"calculate":null},"errors":[{"message":"x value: 1-- not valid number","locations":[{"line":1,"column":3}],"path":["calculate"]}]}`,
+		},
+		{
+			"batch field",
+			http.MethodPost,
+			`{"query":"{ batch(operations: [{action: \"multiply\", x: 2, y: 3}, {action: \"modulo\", x: 2, y: 3}]) { result { answer } error } }"}`,
+			http.StatusOK,
+			`{"data":{"batch":[{"error":null,"result":{"answer":"6"}},{"error":"unknown action: modulo","result":null}]}}`,
+		},
+		{
+			"expression",
+			http.MethodPost,
+			`{"query":"{ evaluate(expression: \"(2 + 3) * 2\") { expression answer cached } }"}`,
+			http.StatusOK,
+			`{"data":{"evaluate":{"answer":"10","cached":false,"expression":"(2 + 3) * 2"}}}`,
+		},
+		{
+			"expression error",
+			http.MethodPost,
+			`{"query":"{ evaluate(expression: \"2 +\") { answer } }"}`,
+			http.StatusOK,
+			`{"data":{"evaluate":null},"errors":[{"message":"unexpected end of expression","locations":[{"line":1,"column":3}],"path":["evaluate"]}]}`,
+		},
+		{
+			"batch of queries",
+			http.MethodPost,
+			`[{"query":"{ calculate(action: \"subtract\", x: 3, y: 2) { answer } }"},{"query":"{ calculate(action: \"multiply\", x: 2, y: 3) { cached } }"}]`,
+			http.StatusOK,
+			`[{"data":{"calculate":{"answer":"1"}}},{"data":{"calculate":{"cached":true}}}]`,
+		},
+		{
+			"get query",
+			http.MethodGet,
+			`{ calculate(action: "divide", x: 1, y: 4) { answer } }`,
+			http.StatusOK,
+			`{"data":{"calculate":{"answer":"0.25"}}}`,
+		},
+		{
+			"too many fields",
+			http.MethodPost,
+			aliases,
+			http.StatusOK,
+			`{"data":null,"errors":[{"message":"query exceeds maximum of 100 fields","locations":[]}]}`,
+		},
+		{
+			"too many operations",
+			http.MethodPost,
+			expressions,
+			http.StatusOK,
+			"[" + strings.Repeat(`{"data":{"evaluate":{"answer":"101"}}},`, 10) +
+				`{"data":null,"errors":[{"message":"request exceeds maximum of 1000 operations","locations":[]}]}]`,
+		},
+		{
+			"invalid body",
+			http.MethodPost,
+			`{"query":`,
+			http.StatusBadRequest,
+			`{"error":"invalid request body: unexpected end of JSON input"}`,
+		},
+	}
+
+	for _, test := range tests {
+		w := httptest.NewRecorder()
+
+		var req *http.Request
+		var err error
+		if test.method == http.MethodGet {
+			req, err = http.NewRequest(test.method, GraphQLEndpoint+"?query="+url.QueryEscape(test.body), nil)
+		} else {
+			req, err = http.NewRequest(test.method, GraphQLEndpoint, strings.NewReader(test.body))
+		}
+
+		assert.NoError(err, "Error should be nil")
+
+		r.ServeHTTP(w, req)
+		assert.Equal(test.status, w.Code, test.name)
+		assert.Equal(test.result, w.Body.String(), test.name)
+	}
+}
+
+func TestGraphQLLimits(t *testing.T) {
+	assert := assert.New(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	authenticator := NewAuthenticator(
+		auth.NewMemoryKeyStore([]auth.Key{
+			{Key: "partner", Name: "partner", Scopes: []string{auth.ScopeArithmeticRead}, DailyQuota: 5},
+		}),
+		auth.NewMemoryUsageStore(),
+		nil,
+	)
+
+	rateLimiter := NewRateLimiter(
+		ratelimit.NewMemoryBackend(1*time.Minute),
+		ratelimit.Limit{},
+		map[string]ratelimit.Limit{MultiplyEndpoint: {Rate: 0.001, Burst: 2}},
+	)
+
+	gin.SetMode(gin.TestMode)
+	r := Router(
+		ctx,
+		logging.New(os.Stdout, logging.DebugLevel),
+		cache.NewStore(10, 1*time.Minute),
+		WithAuthenticator(authenticator),
+		WithRateLimiter(rateLimiter),
+	)
+
+	tests := []struct {
+		name   string
+		query  string
+		result string
+	}{
+		{
+			"batch operations charged to operation rate limit",
+			`{ batch(operations: [{action: "multiply", x: 2, y: 3}, {action: "multiply", x: 2, y: 3}, {action: "multiply", x: 2, y: 3}]) { error } }`,
+			`{"data":{"batch":[{"error":null},{"error":null},{"error":"rate limit exceeded, retry in 1000 seconds"}]}}`,
+		},
+		{
+			"aliases charged to quota",
+			`{ a: calculate(action: "add", x: 1, y: 2) { answer } b: calculate(action: "subtract", x: 1, y: 2) { answer } }`,
+			`{"data":{"a":{"answer":"3"},"b":{"answer":"-1"}}}`,
+		},
+		{
+			"expression operations charged to quota",
+			`{ evaluate(expression: "1 + 2 + 3") { answer } }`,
+			`{"data":{"evaluate":null},"errors":[{"message":"operator + at position 7: daily quota exceeded","locations":[{"line":1,"column":3}],"path":["evaluate"]}]}`,
+		},
+	}
+
+	for _, test := range tests {
+		w := httptest.NewRecorder()
+		req, err := http.NewRequest(http.MethodGet, GraphQLEndpoint+"?query="+url.QueryEscape(test.query), nil)
+		assert.NoError(err, "Error should be nil")
+
+		req.Header.Set(APIKeyHeader, "partner")
+
+		r.ServeHTTP(w, req)
+		assert.Equal(http.StatusOK, w.Code, test.name)
+		assert.JSONEq(test.result, w.Body.String(), test.name)
+	}
+}
@@ -0,0 +1,25 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/pkg/errors"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+)
+
+// H2C returns handler serving HTTP/2 over cleartext and HTTP/1 requests of server with next.
+// h2c connections are hijacked from server, so they are registered for graceful shutdown with server
+// Shutdown which sends them GOAWAY, in-flight streams are not waited for by Shutdown.
+func H2C(server *http.Server, next http.Handler) (http.Handler, error) {
+	h2s := &http2.Server{}
+
+	// ConfigureServer sets TLS config used by TLS connections only, plain server keeps its config.
+	tlsConfig := server.TLSConfig
+	if err := http2.ConfigureServer(server, h2s); err != nil {
+		return nil, errors.Wrap(err, "configuring h2c")
+	}
+	server.TLSConfig = tlsConfig
+
+	return h2c.NewHandler(next, h2s), nil
+}
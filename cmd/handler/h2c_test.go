@@ -0,0 +1,89 @@
+package handler
+
+import (
+	"context"
+	"crypto/tls"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/http2"
+)
+
+func TestH2C(t *testing.T) {
+	assert := assert.New(t)
+
+	started, release := make(chan struct{}, 1), make(chan struct{})
+
+	var inFlight InFlightCounter
+	next := inFlight.Wrap(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			started <- struct{}{}
+			<-release
+		}
+
+		_, _ = w.Write([]byte(r.Proto))
+	}))
+
+	server := &http.Server{}
+	h2cHandler, err := H2C(server, next)
+	assert.NoError(err, "Error should be nil")
+	assert.Nil(server.TLSConfig, "Plain server should not get TLS config")
+	server.Handler = h2cHandler
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(err, "Error should be nil")
+	go server.Serve(listener)
+
+	client := &http.Client{
+		Transport: &http2.Transport{
+			AllowHTTP: true,
+			DialTLS: func(network, addr string, _ *tls.Config) (net.Conn, error) {
+				return net.Dial(network, addr)
+			},
+		},
+	}
+
+	get := func(path string) (string, error) {
+		resp, err := client.Get("http://" + listener.Addr().String() + path)
+		if err != nil {
+			return "", err
+		}
+		defer resp.Body.Close()
+
+		body, err := ioutil.ReadAll(resp.Body)
+		return string(body), err
+	}
+
+	// Test that HTTP/2 is served over cleartext connection
+	body, err := get("/")
+	assert.NoError(err, "Error should be nil")
+	assert.Equal("HTTP/2.0", body)
+
+	slow := make(chan string)
+	go func() {
+		body, err := get("/slow")
+		assert.NoError(err, "In-flight stream should be finished on shutdown")
+		slow <- body
+	}()
+
+	<-started
+	assert.Equal(int64(1), inFlight.Count(), "Stream of h2c connection should be counted")
+
+	// Test that in-flight stream of hijacked connection is drained on shutdown
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	assert.NoError(server.Shutdown(ctx))
+
+	waitCtx, waitCancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer waitCancel()
+	assert.Equal(context.DeadlineExceeded, inFlight.Wait(waitCtx), "Wait should not return while stream is in flight")
+
+	close(release)
+	assert.NoError(inFlight.Wait(ctx))
+	assert.Equal("HTTP/2.0", <-slow)
+}
@@ -0,0 +1,49 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/realmallaury/teltech/internal/cache"
+	"github.com/realmallaury/teltech/internal/logging"
+	"github.com/realmallaury/teltech/internal/version"
+)
+
+// HealthHandler holds data for handling health probes.
+type HealthHandler struct {
+	Logger *logging.Logger
+
+	// ctx is canceled when server starts graceful shutdown.
+	ctx   context.Context
+	store cache.Store
+}
+
+// Liveness reports that process is alive.
+func (hh *HealthHandler) Liveness(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+// Readiness reports whether service can accept requests,
+// it fails when cache store is not reachable or server is shutting down.
+func (hh *HealthHandler) Readiness(c *gin.Context) {
+	if hh.ctx.Err() != nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"status": "shutting down"})
+		return
+	}
+
+	if pinger, ok := hh.store.(cache.Pinger); ok {
+		if err := pinger.Ping(); err != nil {
+			requestLogger(c, hh.Logger).Errorf("Readiness cache store error: %v", err)
+			c.JSON(http.StatusServiceUnavailable, gin.H{"status": "cache store not reachable"})
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+// BuildInfo returns version, commit and build time of running binary.
+func (hh *HealthHandler) BuildInfo(c *gin.Context) {
+	c.JSON(http.StatusOK, version.Get())
+}
@@ -0,0 +1,52 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/realmallaury/teltech/internal/cache"
+	"github.com/realmallaury/teltech/internal/logging"
+	"github.com/realmallaury/teltech/internal/version"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHealth(t *testing.T) {
+	assert := assert.New(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	gin.SetMode(gin.TestMode)
+	r := Router(ctx, logging.New(os.Stdout, logging.DebugLevel), cache.NewStore(10, 1*time.Minute))
+
+	get := func(endpoint string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, err := http.NewRequest(http.MethodGet, endpoint, nil)
+		assert.NoError(err, "Error should be nil")
+
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	assert.Equal(http.StatusOK, get(LivenessEndpoint).Code, "Liveness should be OK")
+	assert.Equal(http.StatusOK, get(ReadinessEndpoint).Code, "Readiness should be OK")
+
+	// Test that build info is returned
+	w := get(VersionEndpoint)
+	assert.Equal(http.StatusOK, w.Code, "Response status should be OK")
+
+	var info version.Info
+	_ = json.Unmarshal(w.Body.Bytes(), &info)
+	assert.Equal(version.Get(), info, "Build info should be the same")
+
+	// Test that readiness fails after shutdown started
+	cancel()
+	assert.Equal(http.StatusOK, get(LivenessEndpoint).Code, "Liveness should be OK")
+	assert.Equal(http.StatusServiceUnavailable, get(ReadinessEndpoint).Code, "Readiness should fail")
+}
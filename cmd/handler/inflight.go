@@ -0,0 +1,48 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// inFlightPollInterval is interval of checking whether requests finished.
+const inFlightPollInterval = 10 * time.Millisecond
+
+// InFlightCounter counts requests which are being handled.
+type InFlightCounter struct {
+	count int64
+}
+
+// Wrap returns handler which counts requests handled by next.
+func (ic *InFlightCounter) Wrap(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(&ic.count, 1)
+		defer atomic.AddInt64(&ic.count, -1)
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Count returns number of requests which are being handled.
+func (ic *InFlightCounter) Count() int64 {
+	return atomic.LoadInt64(&ic.count)
+}
+
+// Wait waits until no requests are being handled or ctx is done, used to wait for requests
+// of hijacked connections which are not tracked by server Shutdown.
+func (ic *InFlightCounter) Wait(ctx context.Context) error {
+	ticker := time.NewTicker(inFlightPollInterval)
+	defer ticker.Stop()
+
+	for ic.Count() > 0 {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+
+	return nil
+}
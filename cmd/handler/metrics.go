@@ -0,0 +1,110 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/realmallaury/teltech/internal/cache"
+)
+
+// Metrics collects request and cache metrics in Prometheus format.
+type Metrics struct {
+	registry *prometheus.Registry
+	requests *prometheus.CounterVec
+	latency  *prometheus.HistogramVec
+	handler  http.Handler
+}
+
+// NewMetrics creates metrics registry with request, cache and Go runtime collectors.
+func NewMetrics(store cache.Store) *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "http_requests_total",
+			Help: "Total number of HTTP requests by endpoint, method and status code.",
+		}, []string{"endpoint", "method", "status"}),
+		latency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "http_request_duration_seconds",
+			Help:    "HTTP request latency by endpoint, method and status code.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"endpoint", "method", "status"}),
+	}
+
+	m.registry.MustRegister(
+		m.requests,
+		m.latency,
+		prometheus.NewGoCollector(),
+		prometheus.NewProcessCollector(prometheus.ProcessCollectorOpts{}),
+	)
+
+	if provider, ok := store.(cache.StatsProvider); ok {
+		m.registry.MustRegister(newCacheCollector(provider))
+	}
+
+	m.handler = promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+
+	return m
+}
+
+// Instrument records request count and latency.
+func (m *Metrics) Instrument(c *gin.Context) {
+	start := time.Now()
+
+	c.Next()
+
+	endpoint := c.FullPath()
+	if endpoint == "" {
+		endpoint = "unmatched"
+	}
+
+	status := strconv.Itoa(c.Writer.Status())
+
+	m.requests.WithLabelValues(endpoint, c.Request.Method, status).Inc()
+	m.latency.WithLabelValues(endpoint, c.Request.Method, status).Observe(time.Since(start).Seconds())
+}
+
+// Handler exposes collected metrics in Prometheus text format.
+func (m *Metrics) Handler(c *gin.Context) {
+	m.handler.ServeHTTP(c.Writer, c.Request)
+}
+
+// cacheCollector exports cache usage counters.
+type cacheCollector struct {
+	provider  cache.StatsProvider
+	hits      *prometheus.Desc
+	misses    *prometheus.Desc
+	evictions *prometheus.Desc
+	entries   *prometheus.Desc
+}
+
+func newCacheCollector(provider cache.StatsProvider) *cacheCollector {
+	return &cacheCollector{
+		provider:  provider,
+		hits:      prometheus.NewDesc("cache_hits_total", "Total number of cache hits.", nil, nil),
+		misses:    prometheus.NewDesc("cache_misses_total", "Total number of cache misses.", nil, nil),
+		evictions: prometheus.NewDesc("cache_evictions_total", "Total number of evicted cache records.", nil, nil),
+		entries:   prometheus.NewDesc("cache_entries", "Current number of cache records.", nil, nil),
+	}
+}
+
+// Describe sends cache metric descriptors.
+func (cc *cacheCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- cc.hits
+	ch <- cc.misses
+	ch <- cc.evictions
+	ch <- cc.entries
+}
+
+// Collect sends current cache metric values.
+func (cc *cacheCollector) Collect(ch chan<- prometheus.Metric) {
+	stats := cc.provider.Stats()
+
+	ch <- prometheus.MustNewConstMetric(cc.hits, prometheus.CounterValue, float64(stats.Hits))
+	ch <- prometheus.MustNewConstMetric(cc.misses, prometheus.CounterValue, float64(stats.Misses))
+	ch <- prometheus.MustNewConstMetric(cc.evictions, prometheus.CounterValue, float64(stats.Evictions))
+	ch <- prometheus.MustNewConstMetric(cc.entries, prometheus.GaugeValue, float64(stats.Len))
+}
@@ -0,0 +1,48 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/realmallaury/teltech/internal/cache"
+	"github.com/realmallaury/teltech/internal/logging"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetrics(t *testing.T) {
+	assert := assert.New(t)
+
+	gin.SetMode(gin.TestMode)
+	logger := logging.New(os.Stdout, logging.DebugLevel)
+	r := Router(context.Background(), logger, cache.NewStore(10, 1*time.Minute))
+
+	// Same request twice to get one cache miss and one cache hit
+	for i := 0; i < 2; i++ {
+		w := httptest.NewRecorder()
+		req, err := http.NewRequest(http.MethodGet, createQueryURL(AddEndpoint, "1", "1"), nil)
+		assert.NoError(err, "Error should be nil")
+
+		r.ServeHTTP(w, req)
+		assert.Equal(http.StatusOK, w.Code, "Response status should be OK")
+	}
+
+	w := httptest.NewRecorder()
+	req, err := http.NewRequest(http.MethodGet, MetricsEndpoint, nil)
+	assert.NoError(err, "Error should be nil")
+
+	r.ServeHTTP(w, req)
+	assert.Equal(http.StatusOK, w.Code, "Response status should be OK")
+
+	body := w.Body.String()
+	assert.Contains(body, `http_requests_total{endpoint="/add",method="GET",status="200"} 2`)
+	assert.Contains(body, `http_request_duration_seconds_count{endpoint="/add",method="GET",status="200"} 2`)
+	assert.Contains(body, "cache_hits_total 1")
+	assert.Contains(body, "cache_misses_total 1")
+	assert.Contains(body, "cache_evictions_total 0")
+	assert.Contains(body, "go_goroutines")
+}
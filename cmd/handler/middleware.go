@@ -1,51 +1,226 @@
 package handler
 
 import (
-	"bytes"
-	"encoding/json"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/realmallaury/teltech/internal/arithmetic"
 	"github.com/realmallaury/teltech/internal/cache"
+	"github.com/realmallaury/teltech/internal/logging"
+	"github.com/realmallaury/teltech/internal/tracing"
 )
 
-// Middleware handles caching results.
-type Middleware struct {
-	store cache.Store
-}
+// RequestIDHeader is header used to pass request id between client and server.
+const RequestIDHeader string = "X-Request-ID"
 
-type bodyWriter struct {
-	gin.ResponseWriter
-	body *bytes.Buffer
-}
+// Gin context keys.
+const (
+	loggerKey    string = "logger"
+	cachedKey    string = "cached"
+	requestIDKey string = "request_id"
+	operandsKey  string = "operands"
+	resultKey    string = "result"
+	versionKey   string = "api_version"
+	formatKey    string = "format"
+
+	numberFormatKey string = "number_format"
+)
 
-func (w *bodyWriter) Write(b []byte) (int, error) {
-	w.body.Write(b)
-	return w.ResponseWriter.Write(b)
+// maxRequestIDLength is maximum length of accepted client request id.
+const maxRequestIDLength int = 128
+
+type requestIDContextKey struct{}
+
+// Middleware handles caching and publishing results.
+type Middleware struct {
+	store  cache.Store
+	logger *logging.Logger
+	tracer *tracing.Tracer
+	events *EventBus
 }
 
-// CacheResult gets result from cache or stores new result if not present.
+// CacheResult gets result of operation set in op path param from cache or stores new result
+// if not present, returned results are published to event bus.
 func (m *Middleware) CacheResult(c *gin.Context) {
-	w := &bodyWriter{body: bytes.NewBuffer([]byte{}), ResponseWriter: c.Writer}
-	c.Writer = w
+	start := time.Now()
+
+	x, y, err := operands(c)
+	if err != nil {
+		c.Next()
+		return
+	}
+
+	// Key doesn't depend on request method, params order or encoding.
+	key := cache.OperationKey(c.Param("op"), x, y)
+
+	_, span := m.tracer.Start(c.Request.Context(), "cache lookup")
+	value, ok := m.store.GetRecord(key)
+	span.SetAttribute("cache.hit", ok)
+	span.End()
 
-	value, ok := m.store.GetRecord(c.Request.URL.String())
 	if ok {
+		requestLogger(c, m.logger).Debugf("Cache hit: %s", key)
+
 		result := value.(arithmetic.Result)
 		result.Cached = true
-		c.AbortWithStatusJSON(http.StatusOK, result)
+		c.Set(cachedKey, true)
+		renderResult(c, result)
+		c.Abort()
+		m.events.Publish(result, time.Since(start))
 		return
 	}
 
 	c.Next()
 
-	if c.Writer.Status() == http.StatusOK {
-		var result arithmetic.Result
+	if value, ok := c.Get(resultKey); ok && c.Writer.Status() == http.StatusOK {
+		result := value.(arithmetic.Result)
+		m.store.StoreRecord(key, result)
+		m.events.Publish(result, time.Since(start))
+	}
+}
+
+// RequestLogger attaches logger to request context and writes log line for each request.
+func RequestLogger(logger *logging.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+
+		fields := logging.Fields{"route": route}
+		if requestID := c.GetString(requestIDKey); requestID != "" {
+			fields[requestIDKey] = requestID
+		}
+
+		if sc := tracing.SpanContextFromContext(c.Request.Context()); sc.IsValid() {
+			fields["trace_id"] = sc.TraceID.String()
+		}
+
+		c.Set(loggerKey, logger.With(fields))
+
+		c.Next()
+
+		level := logging.InfoLevel
+		if c.Writer.Status() >= http.StatusInternalServerError {
+			level = logging.ErrorLevel
+		}
+
+		fields = logging.Fields{
+			"method":     c.Request.Method,
+			"path":       c.Request.URL.Path,
+			"query":      c.Request.URL.RawQuery,
+			"status":     c.Writer.Status(),
+			"latency_ms": float64(time.Since(start).Microseconds()) / 1000,
+			"client_ip":  c.ClientIP(),
+			"cached":     c.GetBool(cachedKey),
+		}
+
+		if identity, ok := getIdentity(c); ok {
+			fields["client"] = identity.Name
+		}
+
+		if len(c.Errors) > 0 {
+			fields["errors"] = c.Errors.String()
+		}
+
+		requestLogger(c, logger).Log(level, "request", fields)
+	}
+}
+
+// requestLogger returns logger attached to request context or fallback logger.
+func requestLogger(c *gin.Context, fallback *logging.Logger) *logging.Logger {
+	if value, ok := c.Get(loggerKey); ok {
+		if logger, ok := value.(*logging.Logger); ok {
+			return logger
+		}
+	}
+
+	return fallback
+}
+
+// RequestID accepts client request id from X-Request-ID header or generates new one,
+// stores it in gin and request context and echoes it in response header.
+func RequestID(c *gin.Context) {
+	requestID := c.GetHeader(RequestIDHeader)
+	if !isRequestIDValid(requestID) {
+		requestID = newRequestID()
+	}
+
+	c.Set(requestIDKey, requestID)
+	c.Request = c.Request.WithContext(context.WithValue(c.Request.Context(), requestIDContextKey{}, requestID))
+	c.Header(RequestIDHeader, requestID)
+
+	c.Next()
+}
+
+// RequestIDFromContext returns request id stored in request context.
+func RequestIDFromContext(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDContextKey{}).(string)
+	return requestID
+}
+
+func isRequestIDValid(requestID string) bool {
+	if requestID == "" || len(requestID) > maxRequestIDLength {
+		return false
+	}
+
+	for _, r := range requestID {
+		if r < '!' || r > '~' {
+			return false
+		}
+	}
+
+	return true
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return hex.EncodeToString([]byte(time.Now().Format(time.RFC3339Nano)))
+	}
+
+	return hex.EncodeToString(b)
+}
+
+// Tracing continues trace from W3C traceparent header or starts new one
+// and creates span for the request.
+func Tracing(tracer *tracing.Tracer) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+		if sc, err := tracing.ParseTraceparent(c.GetHeader(tracing.TraceparentHeader)); err == nil {
+			ctx = tracing.ContextWithRemoteSpanContext(ctx, sc)
+		}
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+
+		ctx, span := tracer.Start(ctx, c.Request.Method+" "+route)
+		defer span.End()
+
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+
+		span.SetAttribute("http.method", c.Request.Method)
+		span.SetAttribute("http.route", route)
+		span.SetAttribute("http.target", c.Request.URL.String())
+		span.SetAttribute("http.status_code", c.Writer.Status())
+
+		if requestID := c.GetString(requestIDKey); requestID != "" {
+			span.SetAttribute(requestIDKey, requestID)
+		}
 
-		if err := json.Unmarshal(w.body.Bytes(), &result); err == nil {
-			m.store.StoreRecord(c.Request.URL.String(), result)
-			return
+		if c.Writer.Status() >= http.StatusInternalServerError {
+			span.SetError(errors.New(http.StatusText(c.Writer.Status())))
 		}
 	}
 }
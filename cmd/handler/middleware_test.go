@@ -0,0 +1,146 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/realmallaury/teltech/internal/cache"
+	"github.com/realmallaury/teltech/internal/logging"
+	"github.com/realmallaury/teltech/internal/tracing"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestID(t *testing.T) {
+	assert := assert.New(t)
+	r, arithmeticHandler := getTestResources()
+
+	r.Use(RequestID)
+	r.GET(AddEndpoint, arithmeticHandler.Add)
+
+	// Test that request id is generated when not sent by client
+	w := httptest.NewRecorder()
+	req, err := http.NewRequest(http.MethodGet, createQueryURL(AddEndpoint, "1", "1"), nil)
+	assert.NoError(err, "Error should be nil")
+
+	r.ServeHTTP(w, req)
+	assert.Equal(http.StatusOK, w.Code, "Response status should be OK")
+	assert.Len(w.Header().Get(RequestIDHeader), 32, "Generated request id should be returned")
+
+	// Test that client request id is echoed in response header and error body
+	w = httptest.NewRecorder()
+	req, err = http.NewRequest(http.MethodGet, createQueryURL(AddEndpoint, "1--", "1"), nil)
+	assert.NoError(err, "Error should be nil")
+	req.Header.Set(RequestIDHeader, "client-id-1")
+
+	r.ServeHTTP(w, req)
+	assert.Equal(http.StatusBadRequest, w.Code, "Response status should be Bad Request")
+	assert.Equal("client-id-1", w.Header().Get(RequestIDHeader), "Client request id should be returned")
+	assert.Equal(
+		`{"error":"x value: 1-- not valid number","request_id":"client-id-1"}`,
+		w.Body.String(),
+		"Response should contain error message and request id",
+	)
+
+	// Test that invalid client request id is replaced
+	w = httptest.NewRecorder()
+	req, err = http.NewRequest(http.MethodGet, createQueryURL(AddEndpoint, "1", "1"), nil)
+	assert.NoError(err, "Error should be nil")
+	req.Header.Set(RequestIDHeader, fmt.Sprintf("%0129d", 0))
+
+	r.ServeHTTP(w, req)
+	assert.Len(w.Header().Get(RequestIDHeader), 32, "Generated request id should be returned")
+}
+
+func TestTracing(t *testing.T) {
+	assert := assert.New(t)
+
+	var buf bytes.Buffer
+	tracer := tracing.New(tracing.NewWriterExporter(&buf), nil)
+
+	gin.SetMode(gin.TestMode)
+	logger := logging.New(os.Stdout, logging.DebugLevel)
+	r := Router(context.Background(), logger, cache.NewStore(10, 1*time.Minute), WithTracer(tracer))
+
+	w := httptest.NewRecorder()
+	req, err := http.NewRequest(http.MethodGet, createQueryURL(AddEndpoint, "1", "1"), nil)
+	assert.NoError(err, "Error should be nil")
+	req.Header.Set(tracing.TraceparentHeader, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+
+	r.ServeHTTP(w, req)
+	assert.Equal(http.StatusOK, w.Code, "Response status should be OK")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Len(lines, 3, "Cache, arithmetic and request spans should be exported")
+
+	names := make([]string, 0, len(lines))
+	for _, line := range lines {
+		var span struct {
+			Name    string `json:"name"`
+			TraceID string `json:"trace_id"`
+		}
+
+		assert.NoError(json.Unmarshal([]byte(line), &span))
+		assert.Equal("4bf92f3577b34da6a3ce929d0e0e4736", span.TraceID, "Trace id should be propagated")
+		names = append(names, span.Name)
+	}
+
+	assert.Equal([]string{"cache lookup", "arithmetic add", "GET /add"}, names)
+}
+
+func TestHTTPSRedirect(t *testing.T) {
+	assert := assert.New(t)
+
+	tables := []struct {
+		tlsHost string
+		host    string
+		target  string
+	}{
+		{"0.0.0.0:8443", "example.com:8080", "https://example.com:8443/add?x=1&y=1"},
+		{":443", "example.com", "https://example.com/add?x=1&y=1"},
+	}
+
+	for _, table := range tables {
+		w := httptest.NewRecorder()
+		req, err := http.NewRequest(http.MethodGet, createQueryURL(AddEndpoint, "1", "1"), nil)
+		assert.NoError(err, "Error should be nil")
+		req.Host = table.host
+
+		HTTPSRedirect(table.tlsHost).ServeHTTP(w, req)
+		assert.Equal(http.StatusPermanentRedirect, w.Code, "Response status should be Permanent Redirect")
+		assert.Equal(table.target, w.Header().Get("Location"), "Values should be the same")
+	}
+}
+
+func TestInFlightCounter(t *testing.T) {
+	assert := assert.New(t)
+
+	var counter InFlightCounter
+
+	started, release := make(chan struct{}), make(chan struct{})
+	handler := counter.Wrap(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+	}))
+
+	done := make(chan struct{})
+	go func() {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, AddEndpoint, nil))
+		close(done)
+	}()
+
+	<-started
+	assert.Equal(int64(1), counter.Count(), "Request should be counted while handled")
+
+	close(release)
+	<-done
+	assert.Equal(int64(0), counter.Count(), "Request should not be counted after handled")
+}
@@ -0,0 +1,607 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/realmallaury/teltech/internal/arithmetic"
+	"github.com/realmallaury/teltech/internal/utils"
+	"github.com/realmallaury/teltech/internal/version"
+)
+
+// OpenAPIHandler serves OpenAPI specification and documentation page.
+type OpenAPIHandler struct {
+	spec []byte
+}
+
+// NewOpenAPIHandler creates handler with OpenAPI specification of all routes.
+func NewOpenAPIHandler() *OpenAPIHandler {
+	spec, _ := json.Marshal(OpenAPISpec())
+
+	return &OpenAPIHandler{
+		spec: spec,
+	}
+}
+
+// Spec returns OpenAPI specification in JSON format.
+func (oh *OpenAPIHandler) Spec(c *gin.Context) {
+	c.Data(http.StatusOK, "application/json; charset=utf-8", oh.spec)
+}
+
+// Docs returns HTML page rendering OpenAPI specification.
+func (oh *OpenAPIHandler) Docs(c *gin.Context) {
+	c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(docsPage))
+}
+
+// OpenAPISpec returns OpenAPI 3 specification of API routes.
+func OpenAPISpec() gin.H {
+	actions := actionNames()
+
+	paths := gin.H{
+		"/v1/calc/{op}":      calcPath("Calculates result of operation", "Result", true, false),
+		"/v2/calc/{op}":      calcPath("Calculates result of operation with operands formatted as strings", "ResultV2", true, false),
+		OperationsEndpoint:   operationsPath(),
+		OperationsV2Endpoint: operationsPath(),
+		RPCEndpoint: gin.H{
+			"post": gin.H{
+				"tags":        []string{"protocols"},
+				"summary":     "JSON-RPC 2.0 endpoint with arithmetic.add, arithmetic.subtract, arithmetic.multiply and arithmetic.divide methods",
+				"security":    protectedSecurity(),
+				"requestBody": jsonBody(gin.H{"oneOf": []gin.H{ref("RPCRequest"), {"type": "array", "items": ref("RPCRequest")}}}),
+				"responses": gin.H{
+					"200": jsonResponse("Response or batch of responses", gin.H{
+						"oneOf": []gin.H{ref("RPCResponse"), {"type": "array", "items": ref("RPCResponse")}},
+					}),
+					"204": gin.H{"description": "All requests were notifications"},
+				},
+			},
+		},
+		GraphQLEndpoint: gin.H{
+			"get": gin.H{
+				"tags":     []string{"protocols"},
+				"summary":  "Executes GraphQL query with calculate and batch fields",
+				"security": protectedSecurity(),
+				"parameters": []gin.H{
+					queryParam("query", "GraphQL query", true),
+					queryParam("variables", "JSON encoded query variables", false),
+					queryParam("operationName", "Name of operation to execute", false),
+				},
+				"responses": graphQLResponses(),
+			},
+			"post": gin.H{
+				"tags":        []string{"protocols"},
+				"summary":     "Executes GraphQL query or batch of queries",
+				"security":    protectedSecurity(),
+				"requestBody": jsonBody(gin.H{"oneOf": []gin.H{ref("GraphQLRequest"), {"type": "array", "items": ref("GraphQLRequest")}}}),
+				"responses":   graphQLResponses(),
+			},
+		},
+		WebSocketEndpoint: gin.H{
+			"get": gin.H{
+				"tags":        []string{"protocols"},
+				"summary":     "Opens WebSocket calculator session",
+				"description": "Messages {\"id\": 1, \"action\": \"add\", \"x\": 1, \"y\": \"ans\", \"as\": \"total\"} are answered with {\"id\": 1, \"result\": {...}} or {\"id\": 1, \"error\": \"...\"}.",
+				"security":    protectedSecurity(),
+				"responses": gin.H{
+					"101": gin.H{"description": "Switching protocols"},
+					"400": errorResponseRef(),
+				},
+			},
+		},
+		EventsEndpoint: gin.H{
+			"get": gin.H{
+				"tags":     []string{"protocols"},
+				"summary":  "Streams computed results as server-sent events, requires admin:events scope",
+				"security": protectedSecurity(),
+				"parameters": []gin.H{{
+					"name":        "action",
+					"in":          "query",
+					"description": "Comma separated actions to stream, all actions are streamed when not set",
+					"schema":      gin.H{"type": "array", "items": gin.H{"type": "string", "enum": actions}},
+					"style":       "form",
+					"explode":     false,
+				}},
+				"responses": gin.H{
+					"200": gin.H{
+						"description": "Stream of result events",
+						"content":     gin.H{"text/event-stream": gin.H{"schema": ref("ResultEvent")}},
+					},
+					"400": errorResponseRef(),
+					"401": errorResponseRef(),
+					"403": errorResponseRef(),
+					"503": errorResponseRef(),
+				},
+			},
+		},
+		UsageEndpoint: gin.H{
+			"get": gin.H{
+				"tags":        []string{"admin"},
+				"summary":     "Returns quota usage of API keys, requires admin:usage scope",
+				"description": "Registered only when authentication is enabled.",
+				"security":    protectedSecurity(),
+				"responses": gin.H{
+					"200": jsonResponse("Usage of API keys", gin.H{"type": "array", "items": ref("Usage")}),
+					"401": errorResponseRef(),
+					"403": errorResponseRef(),
+				},
+			},
+		},
+		CacheEndpoint: gin.H{
+			"get": gin.H{
+				"tags":        []string{"admin"},
+				"summary":     "Returns cache statistics, requires admin:cache scope",
+				"description": "Registered only when authentication is enabled.",
+				"security":    protectedSecurity(),
+				"responses": gin.H{
+					"200": jsonResponse("Cache statistics", ref("CacheStats")),
+					"401": errorResponseRef(),
+					"403": errorResponseRef(),
+				},
+			},
+			"delete": gin.H{
+				"tags":        []string{"admin"},
+				"summary":     "Purges cache, requires admin:cache scope",
+				"description": "Registered only when authentication is enabled.",
+				"security":    protectedSecurity(),
+				"responses": gin.H{
+					"204": gin.H{"description": "Cache purged"},
+					"401": errorResponseRef(),
+					"403": errorResponseRef(),
+				},
+			},
+		},
+		MetricsEndpoint: gin.H{
+			"get": gin.H{
+				"tags":    []string{"operations"},
+				"summary": "Returns metrics in Prometheus text format",
+				"responses": gin.H{
+					"200": gin.H{"description": "Metrics", "content": gin.H{"text/plain": gin.H{"schema": gin.H{"type": "string"}}}},
+				},
+			},
+		},
+		LivenessEndpoint:  healthPath("Liveness probe"),
+		ReadinessEndpoint: healthPath("Readiness probe, fails when cache store is not reachable or shutdown started"),
+		VersionEndpoint: gin.H{
+			"get": gin.H{
+				"tags":      []string{"operations"},
+				"summary":   "Returns build information",
+				"responses": gin.H{"200": jsonResponse("Build information", ref("BuildInfo"))},
+			},
+		},
+		OpenAPIEndpoint: gin.H{
+			"get": gin.H{
+				"tags":      []string{"operations"},
+				"summary":   "Returns this OpenAPI specification",
+				"responses": gin.H{"200": jsonResponse("OpenAPI specification", gin.H{"type": "object"})},
+			},
+		},
+		DocsEndpoint: gin.H{
+			"get": gin.H{
+				"tags":    []string{"operations"},
+				"summary": "Returns API documentation page",
+				"responses": gin.H{
+					"200": gin.H{"description": "Documentation page", "content": gin.H{"text/html": gin.H{"schema": gin.H{"type": "string"}}}},
+				},
+			},
+		},
+	}
+
+	for _, info := range arithmetic.OperationInfos {
+		paths["/"+info.Name] = calcPath(info.Description+" Deprecated, use /v1/calc/"+info.Name+".", "Result", false, true)
+	}
+
+	return gin.H{
+		"openapi": "3.0.3",
+		"info": gin.H{
+			"title":       "Arithmetic API",
+			"description": "Arithmetic operations with cached results.",
+			"version":     version.Get().Version,
+		},
+		"paths": paths,
+		"components": gin.H{
+			"securitySchemes": gin.H{
+				"apiKey":     gin.H{"type": "apiKey", "in": "header", "name": APIKeyHeader},
+				"bearerAuth": gin.H{"type": "http", "scheme": "bearer", "bearerFormat": "JWT"},
+			},
+			"schemas": gin.H{
+				"Result": object(gin.H{
+					"action":      gin.H{"type": "string", "enum": actions},
+					"x":           gin.H{"type": "number"},
+					"y":           gin.H{"type": "number"},
+					"answer":      gin.H{"type": "string", "description": "Answer formatted as string, +Inf or -Inf when out of range"},
+					"numerator":   rationalProperty("Numerator"),
+					"denominator": rationalProperty("Denominator"),
+					"decimal":     rationalProperty("Decimal approximation"),
+					"cached":      gin.H{"type": "boolean"},
+				}),
+				"ResultV2": object(gin.H{
+					"action":      gin.H{"type": "string", "enum": actions},
+					"x":           gin.H{"type": "string"},
+					"y":           gin.H{"type": "string"},
+					"answer":      gin.H{"type": "string"},
+					"numerator":   rationalProperty("Numerator"),
+					"denominator": rationalProperty("Denominator"),
+					"decimal":     rationalProperty("Decimal approximation"),
+					"cached":      gin.H{"type": "boolean"},
+				}),
+				"ResultEvent": gin.H{
+					"allOf": []gin.H{ref("Result"), object(gin.H{"latency_ms": gin.H{"type": "number"}})},
+				},
+				"Error": gin.H{
+					"type":     "object",
+					"required": []string{"error"},
+					"properties": gin.H{
+						"error":      gin.H{"type": "string"},
+						"request_id": gin.H{"type": "string"},
+					},
+				},
+				"Operand": gin.H{
+					"oneOf":       []gin.H{{"type": "number"}, {"type": "string"}},
+					"description": "Integer, float or fraction operand, e.g. 1.5, 0xFF for integer or 1/3 for rational arithmetic",
+				},
+				"Operands": gin.H{
+					"type":                 "object",
+					"required":             []string{"x", "y"},
+					"additionalProperties": false,
+					"properties": gin.H{
+						"x": ref("Operand"),
+						"y": ref("Operand"),
+					},
+				},
+				"Operation": object(gin.H{
+					"name":        gin.H{"type": "string"},
+					"arity":       gin.H{"type": "integer"},
+					"description": gin.H{"type": "string"},
+				}),
+				"RPCRequest": gin.H{
+					"type":     "object",
+					"required": []string{"jsonrpc", "method"},
+					"properties": gin.H{
+						"jsonrpc": gin.H{"type": "string", "enum": []string{rpcVersion}},
+						"method":  gin.H{"type": "string", "example": rpcMethodPrefix + arithmetic.AddConst},
+						"params": gin.H{"oneOf": []gin.H{
+							ref("Operands"),
+							{"type": "array", "items": ref("Operand"), "minItems": 2, "maxItems": 2},
+						}},
+						"id": gin.H{"oneOf": []gin.H{{"type": "string"}, {"type": "number"}}, "nullable": true},
+					},
+				},
+				"RPCResponse": object(gin.H{
+					"jsonrpc": gin.H{"type": "string", "enum": []string{rpcVersion}},
+					"result":  ref("Result"),
+					"error": object(gin.H{
+						"code":    gin.H{"type": "integer"},
+						"message": gin.H{"type": "string"},
+						"data":    gin.H{},
+					}),
+					"id": gin.H{"oneOf": []gin.H{{"type": "string"}, {"type": "number"}}, "nullable": true},
+				}),
+				"GraphQLRequest": gin.H{
+					"type":     "object",
+					"required": []string{"query"},
+					"properties": gin.H{
+						"query":         gin.H{"type": "string"},
+						"variables":     gin.H{"type": "object"},
+						"operationName": gin.H{"type": "string"},
+					},
+				},
+				"GraphQLResponse": object(gin.H{
+					"data":   gin.H{"type": "object"},
+					"errors": gin.H{"type": "array", "items": gin.H{"type": "object"}},
+				}),
+				"Usage": object(gin.H{
+					"name":          gin.H{"type": "string"},
+					"day":           gin.H{"type": "string"},
+					"daily_count":   gin.H{"type": "integer"},
+					"daily_quota":   gin.H{"type": "integer"},
+					"month":         gin.H{"type": "string"},
+					"monthly_count": gin.H{"type": "integer"},
+					"monthly_quota": gin.H{"type": "integer"},
+				}),
+				"CacheStats": object(gin.H{
+					"hits":      gin.H{"type": "integer"},
+					"misses":    gin.H{"type": "integer"},
+					"evictions": gin.H{"type": "integer"},
+					"entries":   gin.H{"type": "integer"},
+				}),
+				"Status": object(gin.H{
+					"status": gin.H{"type": "string"},
+				}),
+				"BuildInfo": object(gin.H{
+					"version":    gin.H{"type": "string"},
+					"commit":     gin.H{"type": "string"},
+					"build_time": gin.H{"type": "string"},
+				}),
+			},
+		},
+	}
+}
+
+// calcPath describes GET and POST operations of arithmetic route.
+func calcPath(summary, result string, opParam, deprecated bool) gin.H {
+	var params []gin.H
+	if opParam {
+		params = append(params, gin.H{
+			"name":     "op",
+			"in":       "path",
+			"required": true,
+			"schema":   gin.H{"type": "string", "enum": actionNames()},
+		})
+	}
+
+	params = append(params, gin.H{
+		"name":        "format",
+		"in":          "query",
+		"description": "Response format, overrides Accept header",
+		"schema":      gin.H{"type": "string", "enum": []string{FormatJSON, FormatXML, FormatCSV, FormatText, FormatMsgPack}},
+	}, gin.H{
+		"name":        "notation",
+		"in":          "query",
+		"description": "Answer notation, auto with decimals set uses fixed notation",
+		"schema": gin.H{
+			"type":    "string",
+			"enum":    []string{utils.NotationAuto, utils.NotationFixed, utils.NotationScientific, utils.NotationEngineering},
+			"default": utils.NotationAuto,
+		},
+	}, gin.H{
+		"name":        "decimals",
+		"in":          "query",
+		"description": "Answer decimal places, shortest representation when not set",
+		"schema":      gin.H{"type": "integer", "minimum": 0, "maximum": utils.MaxDecimals},
+	}, gin.H{
+		"name":        "rounding",
+		"in":          "query",
+		"description": "Answer rounding mode",
+		"schema": gin.H{
+			"type":    "string",
+			"enum":    []string{utils.RoundHalfEven, utils.RoundHalfUp, utils.RoundTruncate},
+			"default": utils.RoundHalfEven,
+		},
+	}, gin.H{
+		"name":        "locale",
+		"in":          "query",
+		"description": "Locale of operand and answer thousands and decimal separators, e.g. en or de-DE, JSON number operands don't depend on locale",
+		"schema":      gin.H{"type": "string"},
+	}, gin.H{
+		"name":        "base",
+		"in":          "query",
+		"description": "Base of answer, operands are calculated with integer arithmetic",
+		"schema":      gin.H{"type": "integer", "minimum": utils.MinBase, "maximum": utils.MaxBase},
+	})
+
+	responses := func(extra ...string) gin.H {
+		r := gin.H{
+			"200": gin.H{
+				"description": "Result of operation in format requested with Accept header or format param",
+				"content": gin.H{
+					gin.MIMEJSON:         gin.H{"schema": ref(result)},
+					gin.MIMEXML:          gin.H{"schema": ref(result)},
+					mimeCSV:              gin.H{"schema": gin.H{"type": "string"}},
+					gin.MIMEPlain:        gin.H{"schema": gin.H{"type": "string", "description": "Answer only"}},
+					binding.MIMEMSGPACK2: gin.H{"schema": ref(result)},
+				},
+			},
+			"400": errorResponseRef(),
+			"401": errorResponseRef(),
+			"403": errorResponseRef(),
+			"406": errorResponseRef(),
+			"429": errorResponseRef(),
+		}
+
+		if opParam {
+			r["404"] = errorResponseRef()
+		}
+
+		for _, status := range extra {
+			r[status] = errorResponseRef()
+		}
+
+		return r
+	}
+
+	post := gin.H{
+		"tags":       []string{"arithmetic"},
+		"summary":    summary,
+		"deprecated": deprecated,
+		"security":   protectedSecurity(),
+		"parameters": params,
+		"requestBody": gin.H{
+			"required": true,
+			"content": gin.H{
+				gin.MIMEJSON:     gin.H{"schema": ref("Operands")},
+				gin.MIMEPOSTForm: gin.H{"schema": ref("Operands")},
+			},
+		},
+		"responses": responses("413", "415"),
+	}
+
+	return gin.H{
+		"get": gin.H{
+			"tags":       []string{"arithmetic"},
+			"summary":    summary,
+			"deprecated": deprecated,
+			"security":   protectedSecurity(),
+			"parameters": append(params, queryParam("x", "First operand", true), queryParam("y", "Second operand", true)),
+			"responses":  responses(),
+		},
+		"post": post,
+	}
+}
+
+func actionNames() []string {
+	actions := make([]string, 0, len(arithmetic.OperationInfos))
+	for _, info := range arithmetic.OperationInfos {
+		actions = append(actions, info.Name)
+	}
+
+	return actions
+}
+
+func operationsPath() gin.H {
+	return gin.H{
+		"get": gin.H{
+			"tags":    []string{"arithmetic"},
+			"summary": "Lists available operations with their arity and descriptions",
+			"responses": gin.H{
+				"200": jsonResponse("Available operations", object(gin.H{
+					"operations": gin.H{"type": "array", "items": ref("Operation")},
+				})),
+			},
+		},
+	}
+}
+
+func healthPath(summary string) gin.H {
+	return gin.H{
+		"get": gin.H{
+			"tags":    []string{"operations"},
+			"summary": summary,
+			"responses": gin.H{
+				"200": jsonResponse("Healthy", ref("Status")),
+				"503": jsonResponse("Unhealthy", ref("Status")),
+			},
+		},
+	}
+}
+
+func graphQLResponses() gin.H {
+	return gin.H{
+		"200": jsonResponse("Query result or batch of results", gin.H{
+			"oneOf": []gin.H{ref("GraphQLResponse"), {"type": "array", "items": ref("GraphQLResponse")}},
+		}),
+		"400": errorResponseRef(),
+	}
+}
+
+// protectedSecurity allows requests with API key or bearer token, or without credentials
+// when authentication is disabled.
+func protectedSecurity() []gin.H {
+	return []gin.H{{"apiKey": []string{}}, {"bearerAuth": []string{}}, {}}
+}
+
+func queryParam(name, description string, required bool) gin.H {
+	return gin.H{
+		"name":        name,
+		"in":          "query",
+		"description": description,
+		"required":    required,
+		"schema":      gin.H{"type": "string"},
+	}
+}
+
+func rationalProperty(description string) gin.H {
+	return gin.H{"type": "string", "description": description + " of rational answer, set when operand is fraction"}
+}
+
+func object(properties gin.H) gin.H {
+	return gin.H{"type": "object", "properties": properties}
+}
+
+func ref(schema string) gin.H {
+	return gin.H{"$ref": "#/components/schemas/" + schema}
+}
+
+func jsonBody(schema gin.H) gin.H {
+	return gin.H{
+		"required": true,
+		"content":  gin.H{gin.MIMEJSON: gin.H{"schema": schema}},
+	}
+}
+
+func jsonResponse(description string, schema gin.H) gin.H {
+	return gin.H{
+		"description": description,
+		"content":     gin.H{gin.MIMEJSON: gin.H{"schema": schema}},
+	}
+}
+
+func errorResponseRef() gin.H {
+	return jsonResponse("Error", ref("Error"))
+}
+
+// docsPage renders operations from OpenAPI specification without external assets.
+const docsPage = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Arithmetic API</title>
+<style>
+body { font-family: sans-serif; margin: 2em auto; max-width: 60em; color: #222; }
+details { border: 1px solid #ccc; border-radius: 4px; margin: .5em 0; padding: .5em; }
+summary { cursor: pointer; }
+.method { display: inline-block; width: 5em; font-weight: bold; text-transform: uppercase; }
+.get { color: #2a7ae2; } .post { color: #2e9e44; } .delete { color: #c0392b; }
+.deprecated { text-decoration: line-through; }
+pre { background: #f5f5f5; padding: .5em; overflow: auto; }
+input { margin: .2em; }
+</style>
+</head>
+<body>
+<h1 id="title">Arithmetic API</h1>
+<p id="description"></p>
+<p><a href="/openapi.json">openapi.json</a></p>
+<div id="operations"></div>
+<script>
+fetch("/openapi.json").then(function (resp) { return resp.json(); }).then(function (spec) {
+  document.getElementById("title").textContent = spec.info.title + " " + spec.info.version;
+  document.getElementById("description").textContent = spec.info.description;
+
+  var container = document.getElementById("operations");
+  Object.keys(spec.paths).sort().forEach(function (path) {
+    Object.keys(spec.paths[path]).forEach(function (method) {
+      var op = spec.paths[path][method];
+      var details = document.createElement("details");
+      var summary = document.createElement("summary");
+      summary.innerHTML = '<span class="method ' + method + '">' + method + '</span>';
+      var name = document.createElement("code");
+      name.textContent = path;
+      if (op.deprecated) { name.className = "deprecated"; }
+      summary.appendChild(name);
+      summary.appendChild(document.createTextNode(" " + op.summary));
+      details.appendChild(summary);
+
+      var params = (op.parameters || []);
+      var form = document.createElement("form");
+      params.forEach(function (param) {
+        var input = document.createElement("input");
+        input.name = param.name;
+        input.placeholder = param.name + " (" + param.in + ")";
+        form.appendChild(input);
+      });
+
+      var output = document.createElement("pre");
+      if (method === "get" && path.indexOf("/ws") !== 0 && path.indexOf("/events") !== 0) {
+        var button = document.createElement("button");
+        button.textContent = "Try it";
+        form.appendChild(button);
+        form.onsubmit = function (e) {
+          e.preventDefault();
+          var url = path;
+          var query = new URLSearchParams();
+          params.forEach(function (param) {
+            var value = form.elements[param.name].value;
+            if (param.in === "path") { url = url.replace("{" + param.name + "}", encodeURIComponent(value)); }
+            else if (value !== "") { query.append(param.name, value); }
+          });
+          if (query.toString()) { url += "?" + query.toString(); }
+          fetch(url).then(function (resp) { return resp.text().then(function (text) {
+            output.textContent = resp.status + " " + url + "\n" + text;
+          }); });
+        };
+      }
+
+      details.appendChild(form);
+      var responses = document.createElement("pre");
+      responses.textContent = "Responses: " + Object.keys(op.responses).join(", ");
+      details.appendChild(responses);
+      details.appendChild(output);
+      container.appendChild(details);
+    });
+  });
+});
+</script>
+</body>
+</html>
+`
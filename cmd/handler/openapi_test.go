@@ -0,0 +1,86 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/realmallaury/teltech/internal/auth"
+	"github.com/realmallaury/teltech/internal/cache"
+	"github.com/realmallaury/teltech/internal/logging"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOpenAPI(t *testing.T) {
+	assert := assert.New(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	gin.SetMode(gin.TestMode)
+	r := Router(
+		ctx,
+		logging.New(os.Stdout, logging.DebugLevel),
+		cache.NewStore(10, 1*time.Minute),
+		WithAuthenticator(NewAuthenticator(auth.NewMemoryKeyStore(nil), auth.NewMemoryUsageStore(), nil)),
+	)
+
+	w := httptest.NewRecorder()
+	req, err := http.NewRequest(http.MethodGet, OpenAPIEndpoint, nil)
+	assert.NoError(err, "Error should be nil")
+
+	r.ServeHTTP(w, req)
+	assert.Equal(http.StatusOK, w.Code, "Response status should be OK")
+
+	var spec struct {
+		OpenAPI    string                                `json:"openapi"`
+		Paths      map[string]map[string]json.RawMessage `json:"paths"`
+		Components struct {
+			Schemas map[string]json.RawMessage `json:"schemas"`
+		} `json:"components"`
+	}
+	assert.NoError(json.Unmarshal(w.Body.Bytes(), &spec))
+	assert.Equal("3.0.3", spec.OpenAPI)
+
+	// Test that every route is described in spec
+	rxParam := regexp.MustCompile(`:([a-zA-Z_]+)`)
+	routes := map[string]bool{}
+
+	for _, route := range r.Routes() {
+		path := rxParam.ReplaceAllString(route.Path, "{$1}")
+		method := strings.ToLower(route.Method)
+		routes[method+" "+path] = true
+
+		_, ok := spec.Paths[path][method]
+		assert.True(ok, "Route should be described in spec: %s %s", route.Method, route.Path)
+	}
+
+	// Test that spec doesn't describe routes which don't exist
+	for path, operations := range spec.Paths {
+		for method := range operations {
+			assert.True(routes[method+" "+path], "Spec path should be routed: %s %s", method, path)
+		}
+	}
+
+	// Test that schema references are defined
+	for _, match := range regexp.MustCompile(`"#/components/schemas/([a-zA-Z0-9]+)"`).FindAllStringSubmatch(w.Body.String(), -1) {
+		_, ok := spec.Components.Schemas[match[1]]
+		assert.True(ok, "Schema should be defined: %s", match[1])
+	}
+
+	// Test that documentation page is served
+	w = httptest.NewRecorder()
+	req, err = http.NewRequest(http.MethodGet, DocsEndpoint, nil)
+	assert.NoError(err, "Error should be nil")
+
+	r.ServeHTTP(w, req)
+	assert.Equal(http.StatusOK, w.Code, "Response status should be OK")
+	assert.Contains(w.Body.String(), OpenAPIEndpoint)
+}
@@ -0,0 +1,174 @@
+package handler
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+	"github.com/realmallaury/teltech/internal/utils"
+)
+
+// requestError is request parsing error with HTTP status code.
+type requestError struct {
+	status int
+	err    error
+}
+
+func (re *requestError) Error() string {
+	return re.err.Error()
+}
+
+type parsedOperands struct {
+	x   string
+	y   string
+	err error
+}
+
+// operands returns x and y from query params of GET requests or from JSON or form body of POST requests,
+// operands are parsed in request locale, normalized and stored in gin context.
+// Operands with 0x, 0o or 0b prefix or requested answer base are converted to integer operands
+// and operands combined with fraction are converted to fractions.
+func operands(c *gin.Context) (string, string, error) {
+	if value, ok := c.Get(operandsKey); ok {
+		parsed := value.(parsedOperands)
+		return parsed.x, parsed.y, parsed.err
+	}
+
+	nf := requestNumberFormat(c)
+
+	var parsed parsedOperands
+	if c.Request.Method == http.MethodGet {
+		parsed.x, parsed.y, parsed.err = utils.NormalizeXY(c.Query("x"), c.Query("y"), nf.Locale)
+	} else {
+		parsed.x, parsed.y, parsed.err = bodyOperands(c, nf.Locale)
+	}
+
+	if parsed.err == nil && (nf.Base != 0 || isIntegerArithmetic(parsed.x, parsed.y)) {
+		parsed.x, parsed.y, parsed.err = utils.IntegerXY(parsed.x, parsed.y)
+
+		if parsed.err == nil && (nf.Notation != utils.NotationAuto || nf.Decimals >= 0) {
+			parsed.err = errors.New("notation and decimals can't be used with integer operands")
+		}
+	} else if parsed.err == nil && (utils.IsFraction(parsed.x) || utils.IsFraction(parsed.y)) {
+		parsed.x, parsed.y, parsed.err = utils.RationalXY(parsed.x, parsed.y)
+	}
+
+	c.Set(operandsKey, parsed)
+	return parsed.x, parsed.y, parsed.err
+}
+
+// errorStatus returns HTTP status code of request error or 400 for other errors.
+func errorStatus(err error) int {
+	if re, ok := err.(*requestError); ok {
+		return re.status
+	}
+
+	return http.StatusBadRequest
+}
+
+func bodyOperands(c *gin.Context, locale utils.Locale) (string, string, error) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxBodySize)
+
+	switch c.ContentType() {
+	case gin.MIMEJSON:
+		return jsonBodyOperands(c.Request.Body, locale)
+
+	case gin.MIMEPOSTForm:
+		return formBodyOperands(c.Request, locale)
+	}
+
+	return "", "", &requestError{
+		status: http.StatusUnsupportedMediaType,
+		err:    errors.Errorf("unsupported content type: %s, expected %s or %s", c.ContentType(), gin.MIMEJSON, gin.MIMEPOSTForm),
+	}
+}
+
+func jsonBodyOperands(body io.Reader, locale utils.Locale) (string, string, error) {
+	var values struct {
+		X json.RawMessage `json:"x"`
+		Y json.RawMessage `json:"y"`
+	}
+
+	decoder := json.NewDecoder(body)
+	decoder.DisallowUnknownFields()
+
+	if err := decoder.Decode(&values); err != nil {
+		return "", "", bodyError(err)
+	}
+
+	if _, err := decoder.Token(); err != io.EOF {
+		return "", "", &requestError{status: http.StatusBadRequest, err: errors.New("invalid request body: unexpected data after JSON object")}
+	}
+
+	x, err := jsonLocaleOperand("x", values.X, locale)
+	if err != nil {
+		return "", "", err
+	}
+
+	y, err := jsonLocaleOperand("y", values.Y, locale)
+	if err != nil {
+		return "", "", err
+	}
+
+	return x, y, nil
+}
+
+// jsonLocaleOperand returns normalized operand, strings are parsed in locale format and numbers don't depend on locale.
+func jsonLocaleOperand(name string, raw json.RawMessage, locale utils.Locale) (string, error) {
+	value, err := jsonOperand(name, raw)
+	if err != nil || raw[0] != '"' {
+		return value, err
+	}
+
+	normalized, ok := utils.NormalizeOperand(value, locale)
+	if !ok {
+		return "", errors.Errorf("%s value: %s not valid number", name, value)
+	}
+
+	return normalized, nil
+}
+
+func formBodyOperands(req *http.Request, locale utils.Locale) (string, string, error) {
+	if err := req.ParseForm(); err != nil {
+		return "", "", bodyError(err)
+	}
+
+	for field, values := range req.PostForm {
+		if field != "x" && field != "y" {
+			return "", "", errors.Errorf("invalid request body: unknown field %q", field)
+		}
+
+		if len(values) > 1 {
+			return "", "", errors.Errorf("invalid request body: field %q set more than once", field)
+		}
+	}
+
+	return utils.NormalizeXY(req.PostForm.Get("x"), req.PostForm.Get("y"), locale)
+}
+
+// bodyError wraps body read error, too large bodies are reported with 413 status.
+func bodyError(err error) error {
+	// MaxBytesReader error has no exported type.
+	if strings.Contains(err.Error(), "request body too large") {
+		return &requestError{status: http.StatusRequestEntityTooLarge, err: errors.Errorf("request body exceeds %d bytes", maxBodySize)}
+	}
+
+	return &requestError{status: http.StatusBadRequest, err: errors.Wrap(err, "invalid request body")}
+}
+
+// requestNumberFormat returns number format of operands and answer set by query params.
+func requestNumberFormat(c *gin.Context) utils.NumberFormat {
+	if value, ok := c.Get(numberFormatKey); ok {
+		return value.(utils.NumberFormat)
+	}
+
+	return utils.DefaultNumberFormat
+}
+
+// isIntegerArithmetic checks weather operands are calculated with integer arithmetic.
+func isIntegerArithmetic(x, y string) bool {
+	return utils.IsRadixInt(x) || utils.IsRadixInt(y)
+}
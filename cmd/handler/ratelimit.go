@@ -0,0 +1,150 @@
+package handler
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+	"github.com/realmallaury/teltech/internal/arithmetic"
+	"github.com/realmallaury/teltech/internal/ratelimit"
+)
+
+// APIKeyHeader is header used by clients to pass API key.
+const APIKeyHeader string = "X-API-Key"
+
+// Rate limit response headers.
+const (
+	RateLimitLimitHeader     string = "RateLimit-Limit"
+	RateLimitRemainingHeader string = "RateLimit-Remaining"
+	RateLimitResetHeader     string = "RateLimit-Reset"
+	RetryAfterHeader         string = "Retry-After"
+)
+
+// RateLimiter limits requests per client and endpoint using token buckets.
+type RateLimiter struct {
+	backend ratelimit.Backend
+
+	mux sync.RWMutex
+	// defaultLimit applies to endpoints without own limit, zero limit disables rate limiting.
+	defaultLimit ratelimit.Limit
+	// endpointLimits are limits by endpoint path, calc routes are limited by operation path, e.g. /multiply.
+	endpointLimits map[string]ratelimit.Limit
+}
+
+// NewRateLimiter creates a new RateLimiter instance.
+func NewRateLimiter(backend ratelimit.Backend, defaultLimit ratelimit.Limit, endpointLimits map[string]ratelimit.Limit) *RateLimiter {
+	rl := &RateLimiter{backend: backend}
+	rl.SetLimits(defaultLimit, endpointLimits)
+
+	return rl
+}
+
+// SetLimits replaces default and per endpoint limits of the running limiter.
+func (rl *RateLimiter) SetLimits(defaultLimit ratelimit.Limit, endpointLimits map[string]ratelimit.Limit) {
+	limits := make(map[string]ratelimit.Limit, len(endpointLimits))
+	for endpoint, limit := range endpointLimits {
+		limits[endpoint] = limit
+	}
+
+	rl.mux.Lock()
+	defer rl.mux.Unlock()
+
+	rl.defaultLimit = defaultLimit
+	rl.endpointLimits = limits
+}
+
+func (rl *RateLimiter) limit(endpoint string) ratelimit.Limit {
+	rl.mux.RLock()
+	defer rl.mux.RUnlock()
+
+	if limit, ok := rl.endpointLimits[endpoint]; ok {
+		return limit
+	}
+
+	return rl.defaultLimit
+}
+
+// Limit rejects requests exceeding client limit for the endpoint with 429 status,
+// clients are identified by authenticated identity or IP address.
+func (rl *RateLimiter) Limit(c *gin.Context) {
+	if rl == nil {
+		return
+	}
+
+	result, err := rl.Take(rateLimitClient(c), rateLimitEndpoint(c))
+	if err != nil {
+		// Backend failures should not block clients, error is logged with request.
+		_ = c.Error(err)
+		return
+	}
+
+	if result.Limit == 0 {
+		return
+	}
+
+	c.Header(RateLimitLimitHeader, strconv.Itoa(result.Limit))
+	c.Header(RateLimitRemainingHeader, strconv.Itoa(result.Remaining))
+	c.Header(RateLimitResetHeader, durationToSeconds(result.Reset))
+
+	if !result.Allowed {
+		c.Header(RetryAfterHeader, durationToSeconds(result.RetryAfter))
+		errorResponse(c, http.StatusTooManyRequests, RateLimitError(result))
+		return
+	}
+}
+
+// Take takes token from client bucket for the endpoint, requests are allowed with zero result limit
+// when endpoint is not limited.
+func (rl *RateLimiter) Take(client, endpoint string) (ratelimit.Result, error) {
+	if rl == nil {
+		return ratelimit.Result{Allowed: true}, nil
+	}
+
+	limit := rl.limit(endpoint)
+	if limit.IsZero() {
+		return ratelimit.Result{Allowed: true}, nil
+	}
+
+	result, err := rl.backend.Take(fmt.Sprintf("%s:%s", client, endpoint), limit, time.Now())
+	if err != nil {
+		return ratelimit.Result{Allowed: true}, errors.Wrap(err, "rate limiter backend")
+	}
+
+	return result, nil
+}
+
+// RateLimitError returns error of rejected request with retry duration.
+func RateLimitError(result ratelimit.Result) error {
+	return fmt.Errorf("rate limit exceeded, retry in %s seconds", durationToSeconds(result.RetryAfter))
+}
+
+// rateLimitEndpoint returns endpoint of request limits, calc routes of all versions are limited
+// by operation path same as legacy routes, e.g. /v1/calc/multiply shares limit and bucket with /multiply.
+func rateLimitEndpoint(c *gin.Context) string {
+	if op := c.Param("op"); op != "" {
+		if _, ok := arithmetic.Operations[op]; ok {
+			return "/" + op
+		}
+	}
+
+	return c.FullPath()
+}
+
+// rateLimitClient identifies client by authenticated identity or IP address,
+// unvalidated API key header is not used so clients can't pick their own bucket.
+func rateLimitClient(c *gin.Context) string {
+	if identity, ok := getIdentity(c); ok {
+		return "id:" + identity.Name
+	}
+
+	return "ip:" + c.ClientIP()
+}
+
+func durationToSeconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
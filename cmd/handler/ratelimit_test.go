@@ -0,0 +1,156 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/realmallaury/teltech/internal/cache"
+	"github.com/realmallaury/teltech/internal/logging"
+	"github.com/realmallaury/teltech/internal/ratelimit"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimiter(t *testing.T) {
+	assert := assert.New(t)
+	r, arithmeticHandler := getTestResources()
+
+	rateLimiter := NewRateLimiter(
+		ratelimit.NewMemoryBackend(1*time.Minute),
+		ratelimit.Limit{Rate: 1, Burst: 1},
+		map[string]ratelimit.Limit{AddEndpoint: {Rate: 0.5, Burst: 2}},
+	)
+
+	r.GET(AddEndpoint, rateLimiter.Limit, arithmeticHandler.Add)
+	r.GET(SubtractEndpoint, rateLimiter.Limit, arithmeticHandler.Subtract)
+
+	get := func(endpoint, remoteAddr string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, err := http.NewRequest(http.MethodGet, createQueryURL(endpoint, "1", "1"), nil)
+		assert.NoError(err, "Error should be nil")
+
+		req.RemoteAddr = remoteAddr
+		if req.RemoteAddr == "" {
+			req.RemoteAddr = "192.0.2.1:1234"
+		}
+
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	// Test that endpoint limit is used
+	w := get(AddEndpoint, "")
+	assert.Equal(http.StatusOK, w.Code, "Response status should be OK")
+	assert.Equal("2", w.Header().Get(RateLimitLimitHeader))
+	assert.Equal("1", w.Header().Get(RateLimitRemainingHeader))
+	assert.Equal("2", w.Header().Get(RateLimitResetHeader))
+
+	assert.Equal(http.StatusOK, get(AddEndpoint, "").Code, "Response status should be OK")
+
+	w = get(AddEndpoint, "")
+	assert.Equal(http.StatusTooManyRequests, w.Code, "Response status should be Too Many Requests")
+	assert.Equal("2", w.Header().Get(RetryAfterHeader))
+	assert.Equal("0", w.Header().Get(RateLimitRemainingHeader))
+	assert.Equal(`{"error":"rate limit exceeded, retry in 2 seconds"}`, w.Body.String())
+
+	// Test that unauthenticated API key doesn't give client own limit
+	w = httptest.NewRecorder()
+	req, err := http.NewRequest(http.MethodGet, createQueryURL(AddEndpoint, "1", "1"), nil)
+	assert.NoError(err, "Error should be nil")
+	req.RemoteAddr = "192.0.2.1:1234"
+	req.Header.Set(APIKeyHeader, "key")
+	r.ServeHTTP(w, req)
+	assert.Equal(http.StatusTooManyRequests, w.Code, "Response status should be Too Many Requests")
+
+	// Test that other clients and endpoints have own limits
+	assert.Equal(http.StatusOK, get(AddEndpoint, "192.0.2.2:1234").Code, "Response status should be OK")
+
+	w = get(SubtractEndpoint, "")
+	assert.Equal(http.StatusOK, w.Code, "Response status should be OK")
+	assert.Equal("1", w.Header().Get(RateLimitLimitHeader))
+	assert.Equal(http.StatusTooManyRequests, get(SubtractEndpoint, "").Code)
+
+	// Test that disabled limits are applied to running limiter
+	rateLimiter.SetLimits(ratelimit.Limit{}, nil)
+	w = get(SubtractEndpoint, "")
+	assert.Equal(http.StatusOK, w.Code, "Response status should be OK")
+	assert.Empty(w.Header().Get(RateLimitLimitHeader))
+}
+
+func TestOperationRateLimit(t *testing.T) {
+	assert := assert.New(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	rateLimiter := NewRateLimiter(
+		ratelimit.NewMemoryBackend(1*time.Minute),
+		ratelimit.Limit{Rate: 0.001, Burst: 1},
+		map[string]ratelimit.Limit{MultiplyEndpoint: {Rate: 0.001, Burst: 2}},
+	)
+
+	gin.SetMode(gin.TestMode)
+	r := Router(ctx, logging.New(os.Stdout, logging.DebugLevel), cache.NewStore(10, 1*time.Minute), WithRateLimiter(rateLimiter))
+
+	tests := []struct {
+		url    string
+		status int
+		limit  string
+	}{
+		{createQueryURL("/v1/calc/multiply", "2", "3"), http.StatusOK, "2"},
+		{createQueryURL("/v2/calc/multiply", "2", "3"), http.StatusOK, "2"},
+		{createQueryURL(MultiplyEndpoint, "2", "3"), http.StatusTooManyRequests, "2"},
+		{createQueryURL("/v1/calc/add", "2", "3"), http.StatusOK, "1"},
+		{createQueryURL("/v2/calc/subtract", "2", "3"), http.StatusOK, "1"},
+		{createQueryURL(AddEndpoint, "2", "3"), http.StatusTooManyRequests, "1"},
+	}
+
+	for _, test := range tests {
+		w := httptest.NewRecorder()
+		req, err := http.NewRequest(http.MethodGet, test.url, nil)
+		assert.NoError(err, "Error should be nil")
+
+		r.ServeHTTP(w, req)
+		assert.Equal(test.status, w.Code, test.url)
+		assert.Equal(test.limit, w.Header().Get(RateLimitLimitHeader), test.url)
+	}
+}
+
+func TestRateLimitForwardedFor(t *testing.T) {
+	assert := assert.New(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	rateLimiter := NewRateLimiter(ratelimit.NewMemoryBackend(1*time.Minute), ratelimit.Limit{Rate: 0.001, Burst: 1}, nil)
+
+	gin.SetMode(gin.TestMode)
+	r := Router(ctx, logging.New(os.Stdout, logging.DebugLevel), cache.NewStore(10, 1*time.Minute), WithRateLimiter(rateLimiter))
+
+	tests := []struct {
+		forwardedFor string
+		status       int
+	}{
+		{"198.51.100.1", http.StatusOK},
+		{"198.51.100.2", http.StatusTooManyRequests},
+		{"198.51.100.3", http.StatusTooManyRequests},
+	}
+
+	// Test that spoofed forwarding headers don't give client new bucket
+	for _, test := range tests {
+		w := httptest.NewRecorder()
+		req, err := http.NewRequest(http.MethodGet, createQueryURL(MultiplyEndpoint, "2", "3"), nil)
+		assert.NoError(err, "Error should be nil")
+
+		req.RemoteAddr = "192.0.2.1:1234"
+		req.Header.Set("X-Forwarded-For", test.forwardedFor)
+		req.Header.Set("X-Real-Ip", test.forwardedFor)
+
+		r.ServeHTTP(w, req)
+		assert.Equal(test.status, w.Code, test.forwardedFor)
+	}
+}
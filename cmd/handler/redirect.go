@@ -0,0 +1,25 @@
+package handler
+
+import (
+	"net"
+	"net/http"
+)
+
+// HTTPSRedirect redirects plain HTTP requests to the same URL on HTTPS server listening on tlsHost.
+func HTTPSRedirect(tlsHost string) http.Handler {
+	_, port, _ := net.SplitHostPort(tlsHost)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host := r.Host
+		if h, _, err := net.SplitHostPort(r.Host); err == nil {
+			host = h
+		}
+
+		if port != "" && port != "443" {
+			host = net.JoinHostPort(host, port)
+		}
+
+		target := "https://" + host + r.URL.RequestURI()
+		http.Redirect(w, r, target, http.StatusPermanentRedirect)
+	})
+}
@@ -0,0 +1,195 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/csv"
+	"math/big"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/gin-gonic/gin/render"
+	"github.com/pkg/errors"
+	"github.com/realmallaury/teltech/internal/arithmetic"
+	"github.com/realmallaury/teltech/internal/utils"
+)
+
+// Response formats of arithmetic results.
+const (
+	FormatJSON    string = "json"
+	FormatXML     string = "xml"
+	FormatCSV     string = "csv"
+	FormatText    string = "text"
+	FormatMsgPack string = "msgpack"
+)
+
+// mimeCSV is CSV media type.
+const mimeCSV string = "text/csv"
+
+// formatMediaTypes maps Accept header media types to response formats.
+var formatMediaTypes = map[string]string{
+	"*/*":                FormatJSON,
+	"application/*":      FormatJSON,
+	gin.MIMEJSON:         FormatJSON,
+	gin.MIMEXML:          FormatXML,
+	gin.MIMEXML2:         FormatXML,
+	mimeCSV:              FormatCSV,
+	gin.MIMEPlain:        FormatText,
+	binding.MIMEMSGPACK:  FormatMsgPack,
+	binding.MIMEMSGPACK2: FormatMsgPack,
+	"text/*":             FormatText,
+}
+
+// negotiateFormat sets response format from format query param or Accept header,
+// JSON is used when neither is set.
+func negotiateFormat(c *gin.Context) {
+	c.Header("Vary", "Accept")
+
+	format, err := requestedFormat(c)
+	if err != nil {
+		errorResponse(c, errorStatus(err), err)
+		return
+	}
+
+	c.Set(formatKey, format)
+	c.Next()
+}
+
+func requestedFormat(c *gin.Context) (string, error) {
+	if format := c.Query("format"); format != "" {
+		switch format {
+		case FormatJSON, FormatXML, FormatCSV, FormatText, FormatMsgPack:
+			return format, nil
+		}
+
+		return "", errors.Errorf("unsupported format: %s, expected json, xml, csv, text or msgpack", format)
+	}
+
+	accept := c.GetHeader("Accept")
+	if accept == "" {
+		return FormatJSON, nil
+	}
+
+	type mediaRange struct {
+		mediaType string
+		quality   float64
+	}
+
+	var ranges []mediaRange
+	for _, part := range strings.Split(accept, ",") {
+		params := strings.Split(part, ";")
+		r := mediaRange{mediaType: strings.ToLower(strings.TrimSpace(params[0])), quality: 1}
+
+		for _, param := range params[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				if q, err := strconv.ParseFloat(strings.TrimPrefix(param, "q="), 64); err == nil {
+					r.quality = q
+				}
+			}
+		}
+
+		if r.quality > 0 {
+			ranges = append(ranges, r)
+		}
+	}
+
+	sort.SliceStable(ranges, func(i, j int) bool {
+		return ranges[i].quality > ranges[j].quality
+	})
+
+	for _, r := range ranges {
+		if format, ok := formatMediaTypes[r.mediaType]; ok {
+			return format, nil
+		}
+
+		if rxVersionMediaType.MatchString(r.mediaType) {
+			return FormatJSON, nil
+		}
+	}
+
+	return "", &requestError{
+		status: http.StatusNotAcceptable,
+		err:    errors.Errorf("unsupported media type: %s, expected JSON, XML, CSV, plain text or MessagePack", accept),
+	}
+}
+
+// negotiateNumberFormat sets answer number format from notation, decimals, rounding and locale query params.
+func negotiateNumberFormat(c *gin.Context) {
+	nf, err := utils.ParseNumberFormat(
+		c.Query("notation"),
+		c.Query("decimals"),
+		c.Query("rounding"),
+		c.Query("locale"),
+		c.Query("base"),
+	)
+	if err != nil {
+		errorResponse(c, http.StatusBadRequest, err)
+		return
+	}
+
+	c.Set(numberFormatKey, nf)
+	c.Next()
+}
+
+// renderResult writes result in format, number format and API version requested by client.
+func renderResult(c *gin.Context, result arithmetic.Result) {
+	// Cached and computed answers are in default number format, so they are reformatted on each response.
+	nf := requestNumberFormat(c)
+
+	if x, y, _ := operands(c); isIntegerArithmetic(x, y) {
+		if answer, ok := new(big.Int).SetString(result.Answer, 10); ok {
+			result.Answer = nf.FormatInt(answer)
+		}
+	} else if result.Denominator != "" {
+		// Rational answer is exact fraction, only its decimal approximation is formatted.
+		if decimal, err := strconv.ParseFloat(result.Decimal, 64); err == nil {
+			result.Decimal = nf.Format(decimal)
+		}
+	} else if answer, err := strconv.ParseFloat(result.Answer, 64); err == nil {
+		result.Answer = nf.Format(answer)
+	}
+
+	serialize, ok := resultSerializers[c.GetString(versionKey)]
+	if !ok {
+		serialize = resultSerializers[DefaultAPIVersion]
+	}
+
+	switch c.GetString(formatKey) {
+	case FormatXML:
+		c.XML(http.StatusOK, serialize(result))
+
+	case FormatCSV:
+		var buf bytes.Buffer
+
+		x, y := exactOperands(result)
+
+		w := csv.NewWriter(&buf)
+		_ = w.Write([]string{"action", "x", "y", "answer", "numerator", "denominator", "decimal", "cached"})
+		_ = w.Write([]string{
+			result.Action,
+			x,
+			y,
+			result.Answer,
+			result.Numerator,
+			result.Denominator,
+			result.Decimal,
+			strconv.FormatBool(result.Cached),
+		})
+		w.Flush()
+
+		c.Data(http.StatusOK, mimeCSV+"; charset=utf-8", buf.Bytes())
+
+	case FormatText:
+		c.String(http.StatusOK, "%s\n", result.Answer)
+
+	case FormatMsgPack:
+		c.Render(http.StatusOK, render.MsgPack{Data: serialize(result)})
+
+	default:
+		c.JSON(http.StatusOK, serialize(result))
+	}
+}
@@ -0,0 +1,223 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/realmallaury/teltech/internal/cache"
+	"github.com/realmallaury/teltech/internal/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/ugorji/go/codec"
+)
+
+func TestRender(t *testing.T) {
+	assert := assert.New(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	gin.SetMode(gin.TestMode)
+	r := Router(ctx, logging.New(os.Stdout, logging.DebugLevel), cache.NewStore(10, 1*time.Minute))
+
+	tests := []struct {
+		name        string
+		url         string
+		accept      string
+		status      int
+		contentType string
+		response    string
+	}{
+		{
+			"default JSON",
+			createQueryURL("/v1/calc/add", "1.5", "2"),
+			"",
+			http.StatusOK,
+			"application/json; charset=utf-8",
+			`{"action":"add","x":1.5,"y":2,"answer":"3.5","cached":false}`,
+		},
+		{
+			"XML from format param",
+			createQueryURL("/v1/calc/add", "1.5", "2") + "&format=xml",
+			"application/json",
+			http.StatusOK,
+			"application/xml; charset=utf-8",
+			`<result><action>add</action><x>1.5</x><y>2</y><answer>3.5</answer><cached>true</cached></result>`,
+		},
+		{
+			"XML version 2",
+			createQueryURL("/v2/calc/add", "1.5", "2"),
+			"application/xml",
+			http.StatusOK,
+			"application/xml; charset=utf-8",
+			`<result><action>add</action><x>1.5</x><y>2</y><answer>3.5</answer><cached>true</cached></result>`,
+		},
+		{
+			"CSV",
+			createQueryURL("/v1/calc/subtract", "1.5", "2"),
+			"text/csv",
+			http.StatusOK,
+			"text/csv; charset=utf-8",
+			"action,x,y,answer,numerator,denominator,decimal,cached\nsubtract,1.5,2,-0.5,,,,false\n",
+		},
+		{
+			"rational CSV",
+			createQueryURL("/v1/calc/add", "1/3", "1/6"),
+			"text/csv",
+			http.StatusOK,
+			"text/csv; charset=utf-8",
+			"action,x,y,answer,numerator,denominator,decimal,cached\nadd,1/3,1/6,1/2,1,2,0.5,false\n",
+		},
+		{
+			"plain text",
+			createQueryURL("/v1/calc/multiply", "1.5", "2"),
+			"text/plain",
+			http.StatusOK,
+			"text/plain; charset=utf-8",
+			"3\n",
+		},
+		{
+			"highest quality media type wins",
+			createQueryURL("/v1/calc/multiply", "1.5", "2"),
+			"application/json; q=0.5, text/plain; q=0.8, image/png",
+			http.StatusOK,
+			"text/plain; charset=utf-8",
+			"3\n",
+		},
+		{
+			"legacy route plain text",
+			createQueryURL(AddEndpoint, "1.5", "2"),
+			"text/plain",
+			http.StatusOK,
+			"text/plain; charset=utf-8",
+			"3.5\n",
+		},
+		{
+			"unsupported media type",
+			createQueryURL("/v1/calc/add", "1.5", "2"),
+			"image/png",
+			http.StatusNotAcceptable,
+			"application/json; charset=utf-8",
+			`{"error":"unsupported media type: image/png, expected JSON, XML, CSV, plain text or MessagePack"}`,
+		},
+		{
+			"unsupported format param",
+			createQueryURL("/v1/calc/add", "1.5", "2") + "&format=yaml",
+			"",
+			http.StatusBadRequest,
+			"application/json; charset=utf-8",
+			`{"error":"unsupported format: yaml, expected json, xml, csv, text or msgpack"}`,
+		},
+	}
+
+	for _, test := range tests {
+		w := httptest.NewRecorder()
+		req, err := http.NewRequest(http.MethodGet, test.url, nil)
+		assert.NoError(err, "Error should be nil")
+
+		req.Header.Set(RequestIDHeader, "id")
+		if test.accept != "" {
+			req.Header.Set("Accept", test.accept)
+		}
+
+		r.ServeHTTP(w, req)
+		assert.Equal(test.status, w.Code, test.name)
+		assert.Equal(test.contentType, w.Header().Get("Content-Type"), test.name)
+		assert.Equal("Accept", w.Header().Get("Vary"), test.name)
+
+		if test.status == http.StatusOK {
+			assert.Equal(test.response, w.Body.String(), test.name)
+		} else {
+			assert.JSONEq(test.response[:len(test.response)-1]+`,"request_id":"id"}`, w.Body.String(), test.name)
+		}
+	}
+
+	// Test MessagePack response
+	w := httptest.NewRecorder()
+	req, err := http.NewRequest(http.MethodGet, createQueryURL("/v1/calc/divide", "3", "2")+"&format=msgpack", nil)
+	assert.NoError(err, "Error should be nil")
+
+	r.ServeHTTP(w, req)
+	assert.Equal(http.StatusOK, w.Code, "Response status should be OK")
+	assert.Equal("application/msgpack; charset=utf-8", w.Header().Get("Content-Type"))
+
+	var result map[string]interface{}
+	assert.NoError(codec.NewDecoderBytes(w.Body.Bytes(), new(codec.MsgpackHandle)).Decode(&result))
+	assert.Equal("divide", string(result["action"].([]byte)))
+	assert.Equal("1.5", string(result["answer"].([]byte)))
+}
+
+func TestNumberFormat(t *testing.T) {
+	assert := assert.New(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	gin.SetMode(gin.TestMode)
+	r := Router(ctx, logging.New(os.Stdout, logging.DebugLevel), cache.NewStore(10, 1*time.Minute))
+
+	tests := []struct {
+		name     string
+		url      string
+		status   int
+		response string
+	}{
+		{
+			"default format",
+			createQueryURL("/v1/calc/multiply", "1e20", "10"),
+			http.StatusOK,
+			`{"action":"multiply","x":100000000000000000000,"y":10,"answer":"1e+21","cached":false}`,
+		},
+		{
+			"cached answer in fixed notation with locale",
+			createQueryURL("/v1/calc/multiply", "1e20", "10") + "&notation=fixed&locale=en",
+			http.StatusOK,
+			`{"action":"multiply","x":100000000000000000000,"y":10,"answer":"1,000,000,000,000,000,000,000","cached":true}`,
+		},
+		{
+			"decimals and rounding",
+			createQueryURL("/v2/calc/divide", "2", "3") + "&decimals=3&rounding=truncate&locale=de",
+			http.StatusOK,
+			`{"action":"divide","x":"2","y":"3","answer":"0,666","cached":false}`,
+		},
+		{
+			"legacy route engineering notation",
+			createQueryURL(MultiplyEndpoint, "1500", "10") + "&notation=engineering",
+			http.StatusOK,
+			`{"action":"multiply","x":1500,"y":10,"answer":"15e+03","cached":false}`,
+		},
+		{
+			"invalid decimals",
+			createQueryURL("/v1/calc/add", "1", "2") + "&decimals=50",
+			http.StatusBadRequest,
+			`{"error":"decimals value: 50 not valid, expected integer from 0 to 20"}`,
+		},
+		{
+			"unsupported locale",
+			createQueryURL("/v1/calc/add", "1", "2") + "&locale=xx",
+			http.StatusBadRequest,
+			`{"error":"unsupported locale: xx"}`,
+		},
+	}
+
+	for _, test := range tests {
+		w := httptest.NewRecorder()
+		req, err := http.NewRequest(http.MethodGet, test.url, nil)
+		assert.NoError(err, "Error should be nil")
+
+		req.Header.Set(RequestIDHeader, "id")
+
+		r.ServeHTTP(w, req)
+		assert.Equal(test.status, w.Code, test.name)
+
+		if test.status == http.StatusOK {
+			assert.Equal(test.response, w.Body.String(), test.name)
+		} else {
+			assert.JSONEq(test.response[:len(test.response)-1]+`,"request_id":"id"}`, w.Body.String(), test.name)
+		}
+	}
+}
@@ -0,0 +1,15 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+// errorResponse writes error message with request id in JSON response.
+func errorResponse(c *gin.Context, status int, err error) {
+	body := gin.H{"error": err.Error()}
+	if requestID := c.GetString(requestIDKey); requestID != "" {
+		body[requestIDKey] = requestID
+	}
+
+	c.AbortWithStatusJSON(status, body)
+}
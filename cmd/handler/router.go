@@ -2,43 +2,252 @@ package handler
 
 import (
 	"context"
-	"log"
+	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/realmallaury/teltech/internal/auth"
 	"github.com/realmallaury/teltech/internal/cache"
+	"github.com/realmallaury/teltech/internal/logging"
+	"github.com/realmallaury/teltech/internal/tracing"
 )
 
 // URL endpoint constants.
 const (
-	AddEndpoint      string = "/add"
-	SubtractEndpoint string = "/subtract"
-	MultiplyEndpoint string = "/multiply"
-	DivideEndpoint   string = "/divide"
+	AddEndpoint          string = "/add"
+	SubtractEndpoint     string = "/subtract"
+	MultiplyEndpoint     string = "/multiply"
+	DivideEndpoint       string = "/divide"
+	MetricsEndpoint      string = "/metrics"
+	LivenessEndpoint     string = "/healthz"
+	ReadinessEndpoint    string = "/readyz"
+	VersionEndpoint      string = "/version"
+	UsageEndpoint        string = "/admin/usage"
+	CacheEndpoint        string = "/admin/cache"
+	RPCEndpoint          string = "/rpc"
+	GraphQLEndpoint      string = "/graphql"
+	WebSocketEndpoint    string = "/ws"
+	EventsEndpoint       string = "/events"
+	CalcEndpoint         string = "/v1/calc/:op"
+	OperationsEndpoint   string = "/v1/operations"
+	CalcV2Endpoint       string = "/v2/calc/:op"
+	OperationsV2Endpoint string = "/v2/operations"
+	OpenAPIEndpoint      string = "/openapi.json"
+	DocsEndpoint         string = "/docs"
 )
 
-// Router initializes handler and middleware for API routes.
-func Router(ctx context.Context, logger *log.Logger, store cache.Store) *gin.Engine {
+// Option configures optional Router dependencies.
+type Option func(*options)
+
+type options struct {
+	tracer        *tracing.Tracer
+	rateLimiter   *RateLimiter
+	authenticator *Authenticator
+	events        *EventBus
+}
+
+// WithTracer sets tracer used to create request, cache and arithmetic spans.
+func WithTracer(tracer *tracing.Tracer) Option {
+	return func(o *options) {
+		o.tracer = tracer
+	}
+}
+
+// WithRateLimiter sets rate limiter applied to arithmetic endpoints.
+func WithRateLimiter(rateLimiter *RateLimiter) Option {
+	return func(o *options) {
+		o.rateLimiter = rateLimiter
+	}
+}
+
+// WithAuthenticator sets authenticator which protects arithmetic and admin endpoints,
+// admin usage and cache endpoints are registered only when authenticator is set.
+func WithAuthenticator(authenticator *Authenticator) Option {
+	return func(o *options) {
+		o.authenticator = authenticator
+	}
+}
+
+// WithEventBus sets event bus which receives computed results and feeds events endpoint,
+// new bus is created when it is not set.
+func WithEventBus(events *EventBus) Option {
+	return func(o *options) {
+		o.events = events
+	}
+}
+
+// Router initializes handler and middleware for API routes,
+// readiness endpoint starts failing when ctx is canceled.
+func Router(ctx context.Context, logger *logging.Logger, store cache.Store, opts ...Option) *gin.Engine {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	if o.events == nil {
+		o.events = NewEventBus()
+	}
+
 	router := gin.New()
 
-	// Middleware will write the logs to specified writer
-	router.Use(gin.Logger())
+	// Client IP is read from connection address only, so clients can't pick own rate limit bucket
+	// with X-Forwarded-For or X-Real-Ip headers.
+	router.ForwardedByClientIP = false
+
+	// Middleware accepts or generates request id used for request correlation.
+	router.Use(RequestID)
+
+	// Middleware creates request span continuing trace from traceparent header.
+	router.Use(Tracing(o.tracer))
+
+	// Middleware attaches request logger and writes structured log line for each request.
+	router.Use(RequestLogger(logger))
 
 	// Middleware recovers from any panics and writes a 500 if there was one.
 	router.Use(gin.Recovery())
 
+	// Middleware collects request metrics exposed on metrics endpoint.
+	metrics := NewMetrics(store)
+	router.Use(metrics.Instrument)
+
 	// Custom middleware for caching result.
 	middlewareHandler := Middleware{
-		store: store,
+		store:  store,
+		logger: logger,
+		tracer: o.tracer,
+		events: o.events,
 	}
 
 	arithmeticHandler := ArithmeticHandler{
 		Logger: logger,
+		Tracer: o.tracer,
 	}
 
-	router.GET(AddEndpoint, middlewareHandler.CacheResult, arithmeticHandler.Add)
-	router.GET(SubtractEndpoint, middlewareHandler.CacheResult, arithmeticHandler.Subtract)
-	router.GET(MultiplyEndpoint, middlewareHandler.CacheResult, arithmeticHandler.Multiply)
-	router.GET(DivideEndpoint, middlewareHandler.CacheResult, arithmeticHandler.Divide)
+	protectedRoutes := router.Group(
+		"",
+		o.authenticator.Authenticate,
+		o.authenticator.RequireScope(auth.ScopeArithmeticRead),
+		o.rateLimiter.Limit,
+		o.authenticator.EnforceQuota,
+	)
+
+	// Version is set by path prefix, results are serialized for the version.
+	v1Routes := protectedRoutes.Group("", withAPIVersion(APIVersion1), negotiateFormat, negotiateNumberFormat, middlewareHandler.CacheResult)
+
+	v1Routes.GET(CalcEndpoint, arithmeticHandler.Calculate)
+	v1Routes.POST(CalcEndpoint, arithmeticHandler.Calculate)
+
+	v2Routes := protectedRoutes.Group("", withAPIVersion(APIVersion2), negotiateFormat, negotiateNumberFormat, middlewareHandler.CacheResult)
+
+	v2Routes.GET(CalcV2Endpoint, arithmeticHandler.Calculate)
+	v2Routes.POST(CalcV2Endpoint, arithmeticHandler.Calculate)
+
+	// Legacy routes are served through compatibility layer which maps them to operations of calc endpoint,
+	// version is negotiated with Accept header.
+	legacyRoutes := protectedRoutes.Group(
+		"",
+		deprecated,
+		negotiateAPIVersion,
+		negotiateFormat,
+		negotiateNumberFormat,
+		legacyOperation,
+		middlewareHandler.CacheResult,
+	)
+
+	legacyRoutes.GET(AddEndpoint, arithmeticHandler.Add)
+	legacyRoutes.GET(SubtractEndpoint, arithmeticHandler.Subtract)
+	legacyRoutes.GET(MultiplyEndpoint, arithmeticHandler.Multiply)
+	legacyRoutes.GET(DivideEndpoint, arithmeticHandler.Divide)
+	legacyRoutes.POST(AddEndpoint, arithmeticHandler.Add)
+	legacyRoutes.POST(SubtractEndpoint, arithmeticHandler.Subtract)
+	legacyRoutes.POST(MultiplyEndpoint, arithmeticHandler.Multiply)
+	legacyRoutes.POST(DivideEndpoint, arithmeticHandler.Divide)
+
+	calculator := NewCalculator(
+		logger,
+		store,
+		WithTracer(o.tracer),
+		WithEventBus(o.events),
+		WithRateLimiter(o.rateLimiter),
+		WithAuthenticator(o.authenticator),
+	)
+
+	rpcHandler := RPCHandler{
+		Logger:     logger,
+		Calculator: calculator,
+	}
+
+	// Request of calculator routes takes rate limit token of endpoint, each operation of request is charged
+	// to rate limit of its operation path and API key quota by calculator.
+	calculatorRoutes := router.Group(
+		"",
+		o.authenticator.Authenticate,
+		o.authenticator.RequireScope(auth.ScopeArithmeticRead),
+		o.rateLimiter.Limit,
+		withCaller,
+	)
+
+	calculatorRoutes.POST(RPCEndpoint, rpcHandler.Serve)
+
+	graphQLHandler := NewGraphQLHandler(logger, calculator)
+
+	calculatorRoutes.GET(GraphQLEndpoint, graphQLHandler.Serve)
+	calculatorRoutes.POST(GraphQLEndpoint, graphQLHandler.Serve)
+
+	webSocketHandler := NewWebSocketHandler(ctx, logger, calculator, o.rateLimiter)
+
+	calculatorRoutes.GET(WebSocketEndpoint, webSocketHandler.Serve)
+
+	eventsHandler := EventsHandler{
+		ctx: ctx,
+		bus: o.events,
+	}
+
+	// Events of all callers are streamed, so stream requires admin scope instead of arithmetic:read.
+	eventsRoutes := router.Group(
+		"",
+		o.authenticator.Authenticate,
+		o.authenticator.RequireScope(auth.ScopeAdminEvents),
+		o.rateLimiter.Limit,
+		o.authenticator.EnforceQuota,
+	)
+
+	eventsRoutes.GET(EventsEndpoint, eventsHandler.Stream)
+
+	if o.authenticator != nil {
+		adminHandler := AdminHandler{
+			store: store,
+		}
+
+		adminRoutes := router.Group("", o.authenticator.Authenticate)
+
+		adminRoutes.GET(UsageEndpoint, o.authenticator.RequireScope(auth.ScopeAdminUsage), o.authenticator.Usage)
+		adminRoutes.GET(CacheEndpoint, o.authenticator.RequireScope(auth.ScopeAdminCache), adminHandler.CacheStats)
+		adminRoutes.DELETE(CacheEndpoint, o.authenticator.RequireScope(auth.ScopeAdminCache), adminHandler.PurgeCache)
+	}
+
+	healthHandler := HealthHandler{
+		Logger: logger,
+		ctx:    ctx,
+		store:  store,
+	}
+
+	router.GET(OperationsEndpoint, withAPIVersion(APIVersion1), arithmeticHandler.Operations)
+	router.GET(OperationsV2Endpoint, withAPIVersion(APIVersion2), arithmeticHandler.Operations)
+	openAPIHandler := NewOpenAPIHandler()
+
+	router.GET(OpenAPIEndpoint, openAPIHandler.Spec)
+	router.GET(DocsEndpoint, openAPIHandler.Docs)
+	router.GET(MetricsEndpoint, metrics.Handler)
+	router.GET(LivenessEndpoint, healthHandler.Liveness)
+	router.GET(ReadinessEndpoint, healthHandler.Readiness)
+	router.GET(VersionEndpoint, healthHandler.BuildInfo)
 
 	return router
 }
+
+// legacyOperation sets op path param of legacy routes, e.g. /add, to operation name
+// so they share cache and handling with calc endpoint.
+func legacyOperation(c *gin.Context) {
+	c.Params = append(c.Params, gin.Param{Key: "op", Value: strings.TrimPrefix(c.FullPath(), "/")})
+	c.Next()
+}
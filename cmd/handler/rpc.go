@@ -0,0 +1,262 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+	"github.com/realmallaury/teltech/internal/arithmetic"
+	"github.com/realmallaury/teltech/internal/logging"
+)
+
+// JSON-RPC 2.0 error codes.
+const (
+	RPCParseError     int = -32700
+	RPCInvalidRequest int = -32600
+	RPCMethodNotFound int = -32601
+	RPCInvalidParams  int = -32602
+	RPCInternalError  int = -32603
+	// RPCLimitExceeded is server error of call rejected by rate limit or API key quota.
+	RPCLimitExceeded int = -32000
+)
+
+const (
+	rpcVersion string = "2.0"
+
+	// rpcMethodPrefix is prefix of arithmetic method names, e.g. arithmetic.add.
+	rpcMethodPrefix string = "arithmetic."
+)
+
+// RPCHandler serves arithmetic operations over JSON-RPC 2.0.
+type RPCHandler struct {
+	Logger     *logging.Logger
+	Calculator *Calculator
+}
+
+type rpcRequest struct {
+	JSONRPC string          `json:"jsonrpc"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params"`
+	ID      json.RawMessage `json:"id"`
+}
+
+type rpcResponse struct {
+	JSONRPC string             `json:"jsonrpc"`
+	Result  *arithmetic.Result `json:"result,omitempty"`
+	Error   *rpcError          `json:"error,omitempty"`
+	ID      json.RawMessage    `json:"id"`
+}
+
+type rpcError struct {
+	Code    int         `json:"code"`
+	Message string      `json:"message"`
+	Data    interface{} `json:"data,omitempty"`
+}
+
+func newRPCError(code int, data interface{}) *rpcError {
+	messages := map[int]string{
+		RPCParseError:     "Parse error",
+		RPCInvalidRequest: "Invalid Request",
+		RPCMethodNotFound: "Method not found",
+		RPCInvalidParams:  "Invalid params",
+		RPCInternalError:  "Internal error",
+		RPCLimitExceeded:  "Limit exceeded",
+	}
+
+	return &rpcError{Code: code, Message: messages[code], Data: data}
+}
+
+// Serve handles single and batch JSON-RPC requests, responds with 204 when all requests are notifications.
+func (rh *RPCHandler) Serve(c *gin.Context) {
+	body, err := ioutil.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxBodySize))
+	if err != nil {
+		c.JSON(http.StatusOK, rpcResponse{JSONRPC: rpcVersion, Error: newRPCError(RPCParseError, err.Error())})
+		return
+	}
+
+	body = bytes.TrimSpace(body)
+	if len(body) == 0 || body[0] != '[' {
+		resp, ok := rh.handle(c, body)
+		if !ok {
+			c.Status(http.StatusNoContent)
+			return
+		}
+
+		c.JSON(http.StatusOK, resp)
+		return
+	}
+
+	var batch []json.RawMessage
+	if err := json.Unmarshal(body, &batch); err != nil {
+		c.JSON(http.StatusOK, rpcResponse{JSONRPC: rpcVersion, Error: newRPCError(RPCParseError, err.Error())})
+		return
+	}
+
+	if len(batch) == 0 {
+		c.JSON(http.StatusOK, rpcResponse{JSONRPC: rpcVersion, Error: newRPCError(RPCInvalidRequest, "empty batch")})
+		return
+	}
+
+	if len(batch) > maxBatchSize {
+		err := errors.Errorf("batch size: %d exceeds maximum: %d", len(batch), maxBatchSize)
+		c.JSON(http.StatusOK, rpcResponse{JSONRPC: rpcVersion, Error: newRPCError(RPCInvalidRequest, err.Error())})
+		return
+	}
+
+	responses := make([]rpcResponse, 0, len(batch))
+	for _, raw := range batch {
+		if resp, ok := rh.handle(c, raw); ok {
+			responses = append(responses, resp)
+		}
+	}
+
+	if len(responses) == 0 {
+		c.Status(http.StatusNoContent)
+		return
+	}
+
+	c.JSON(http.StatusOK, responses)
+}
+
+// handle processes single request, returns false when no response should be sent for notification.
+func (rh *RPCHandler) handle(c *gin.Context, raw json.RawMessage) (rpcResponse, bool) {
+	resp := rpcResponse{JSONRPC: rpcVersion}
+
+	var req rpcRequest
+	if err := json.Unmarshal(raw, &req); err != nil {
+		if _, ok := err.(*json.SyntaxError); ok {
+			resp.Error = newRPCError(RPCParseError, err.Error())
+		} else {
+			resp.Error = newRPCError(RPCInvalidRequest, err.Error())
+		}
+
+		return resp, true
+	}
+
+	if !isRPCIDValid(req.ID) {
+		resp.Error = newRPCError(RPCInvalidRequest, "id must be string, number or null")
+		return resp, true
+	}
+
+	resp.ID = req.ID
+	notification := req.ID == nil
+
+	if req.JSONRPC != rpcVersion || req.Method == "" {
+		resp.Error = newRPCError(RPCInvalidRequest, `jsonrpc must be "2.0" and method must be set`)
+		return resp, true
+	}
+
+	action := strings.TrimPrefix(req.Method, rpcMethodPrefix)
+	if _, ok := arithmetic.Operations[action]; !ok || !strings.HasPrefix(req.Method, rpcMethodPrefix) {
+		resp.Error = newRPCError(RPCMethodNotFound, req.Method)
+		return resp, !notification
+	}
+
+	x, y, err := rpcParams(req.Params)
+	if err != nil {
+		resp.Error = newRPCError(RPCInvalidParams, err.Error())
+		return resp, !notification
+	}
+
+	// Each call of batch, including notifications, is charged to rate limit and quota of its operation.
+	result, err := rh.Calculator.Calculate(c.Request.Context(), action, x, y)
+	if err != nil {
+		requestLogger(c, rh.Logger).Warnf("RPC %s method error: %v", req.Method, err)
+
+		code := RPCInvalidParams
+		if IsLimitError(err) {
+			code = RPCLimitExceeded
+		}
+
+		resp.Error = newRPCError(code, err.Error())
+		return resp, !notification
+	}
+
+	resp.Result = result
+	return resp, !notification
+}
+
+// rpcParams returns x and y operands from {"x": 1, "y": 2} or [1, 2] params,
+// operands can be numbers or strings.
+func rpcParams(params json.RawMessage) (string, string, error) {
+	var values []json.RawMessage
+
+	switch trimmed := bytes.TrimSpace(params); {
+	case len(trimmed) > 0 && trimmed[0] == '{':
+		var named struct {
+			X json.RawMessage `json:"x"`
+			Y json.RawMessage `json:"y"`
+		}
+
+		if err := json.Unmarshal(trimmed, &named); err != nil {
+			return "", "", err
+		}
+
+		values = []json.RawMessage{named.X, named.Y}
+
+	case len(trimmed) > 0 && trimmed[0] == '[':
+		if err := json.Unmarshal(trimmed, &values); err != nil {
+			return "", "", err
+		}
+
+		if len(values) != 2 {
+			return "", "", errors.Errorf("expected 2 params, got: %d", len(values))
+		}
+
+	default:
+		return "", "", errors.New("params must be object with x and y or array of 2 operands")
+	}
+
+	x, err := jsonOperand("x", values[0])
+	if err != nil {
+		return "", "", err
+	}
+
+	y, err := jsonOperand("y", values[1])
+	if err != nil {
+		return "", "", err
+	}
+
+	return x, y, nil
+}
+
+// jsonOperand returns operand passed as JSON number or string.
+func jsonOperand(name string, raw json.RawMessage) (string, error) {
+	if raw == nil {
+		return "", errors.Errorf("%s value missing", name)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return "", err
+	}
+
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case json.Number:
+		return v.String(), nil
+	}
+
+	return "", errors.Errorf("%s value: %s not valid number", name, raw)
+}
+
+func isRPCIDValid(id json.RawMessage) bool {
+	if id == nil {
+		return true
+	}
+
+	switch id[0] {
+	case '"', 'n', '-', '0', '1', '2', '3', '4', '5', '6', '7', '8', '9':
+		return true
+	}
+
+	return false
+}
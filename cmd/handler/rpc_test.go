@@ -0,0 +1,195 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/realmallaury/teltech/internal/auth"
+	"github.com/realmallaury/teltech/internal/cache"
+	"github.com/realmallaury/teltech/internal/logging"
+	"github.com/realmallaury/teltech/internal/ratelimit"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRPC(t *testing.T) {
+	assert := assert.New(t)
+
+	rpcHandler := RPCHandler{
+		Logger:     logging.New(os.Stdout, logging.DebugLevel),
+		Calculator: NewCalculator(logging.New(os.Stdout, logging.DebugLevel), cache.NewStore(10, 1*time.Minute)),
+	}
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.POST(RPCEndpoint, rpcHandler.Serve)
+
+	tests := []struct {
+		name   string
+		body   string
+		status int
+		result string
+	}{
+		{
+			"named params",
+			`{"jsonrpc":"2.0","method":"arithmetic.add","params":{"x":1,"y":"2"},"id":1}`,
+			http.StatusOK,
+			`{"jsonrpc":"2.0","result":{"action":"add","x":1,"y":2,"answer":"3","cached":false},"id":1}`,
+		},
+		{
+			"positional params and cached result",
+			`{"jsonrpc":"2.0","method":"arithmetic.add","params":["1",2],"id":"a"}`,
+			http.StatusOK,
+			`{"jsonrpc":"2.0","result":{"action":"add","x":1,"y":2,"answer":"3","cached":true},"id":"a"}`,
+		},
+		{
+			"validation error",
+			`{"jsonrpc":"2.0","method":"arithmetic.divide","params":{"x":"1--","y":"2"},"id":2}`,
+			http.StatusOK,
+			`{"jsonrpc":"2.0","error":{"code":-32602,"message":"Invalid params","data":"x value: 1-- not valid number"},"id":2}`,
+		},
+		{
+			"missing operand",
+			`{"jsonrpc":"2.0","method":"arithmetic.divide","params":{"x":1},"id":3}`,
+			http.StatusOK,
+			`{"jsonrpc":"2.0","error":{"code":-32602,"message":"Invalid params","data":"y value missing"},"id":3}`,
+		},
+		{
+			"unknown method",
+			`{"jsonrpc":"2.0","method":"arithmetic.modulo","params":[1,2],"id":4}`,
+			http.StatusOK,
+			`{"jsonrpc":"2.0","error":{"code":-32601,"message":"Method not found","data":"arithmetic.modulo"},"id":4}`,
+		},
+		{
+			"invalid version",
+			`{"jsonrpc":"1.0","method":"arithmetic.add","params":[1,2],"id":5}`,
+			http.StatusOK,
+			`{"jsonrpc":"2.0","error":{"code":-32600,"message":"Invalid Request","data":"jsonrpc must be \"2.0\" and method must be set"},"id":5}`,
+		},
+		{
+			"parse error",
+			`{"jsonrpc":"2.0","method"`,
+			http.StatusOK,
+			`{"jsonrpc":"2.0","error":{"code":-32700,"message":"Parse error","data":"unexpected end of JSON input"},"id":null}`,
+		},
+		{
+			"notification",
+			`{"jsonrpc":"2.0","method":"arithmetic.add","params":[1,2]}`,
+			http.StatusNoContent,
+			``,
+		},
+		{
+			"empty batch",
+			`[]`,
+			http.StatusOK,
+			`{"jsonrpc":"2.0","error":{"code":-32600,"message":"Invalid Request","data":"empty batch"},"id":null}`,
+		},
+		{
+			"batch",
+			`[
+				{"jsonrpc":"2.0","method":"arithmetic.multiply","params":[2,3],"id":1},
+				{"jsonrpc":"2.0","method":"arithmetic.subtract","params":[2,3]},
+				1
+			]`,
+			http.StatusOK,
+			`[{"jsonrpc":"2.0","result":{"action":"multiply","x":2,"y":3,"answer":"6","cached":false},"id":1},` +
+				`{"jsonrpc":"2.0","error":{"code":-32600,"message":"Invalid Request","data":"json: cannot unmarshal number into Go value of type handler.rpcRequest"},"id":null}]`,
+		},
+		{
+			"batch of notifications",
+			`[{"jsonrpc":"2.0","method":"arithmetic.add","params":[1,2]}]`,
+			http.StatusNoContent,
+			``,
+		},
+	}
+
+	for _, test := range tests {
+		w := httptest.NewRecorder()
+		req, err := http.NewRequest(http.MethodPost, RPCEndpoint, strings.NewReader(test.body))
+		assert.NoError(err, "Error should be nil")
+
+		r.ServeHTTP(w, req)
+		assert.Equal(test.status, w.Code, test.name)
+		assert.Equal(test.result, w.Body.String(), test.name)
+	}
+}
+
+func TestRPCLimits(t *testing.T) {
+	assert := assert.New(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	authenticator := NewAuthenticator(
+		auth.NewMemoryKeyStore([]auth.Key{
+			{Key: "partner", Name: "partner", Scopes: []string{auth.ScopeArithmeticRead}, DailyQuota: 4},
+		}),
+		auth.NewMemoryUsageStore(),
+		nil,
+	)
+
+	rateLimiter := NewRateLimiter(
+		ratelimit.NewMemoryBackend(1*time.Minute),
+		ratelimit.Limit{},
+		map[string]ratelimit.Limit{MultiplyEndpoint: {Rate: 0.001, Burst: 2}},
+	)
+
+	gin.SetMode(gin.TestMode)
+	r := Router(
+		ctx,
+		logging.New(os.Stdout, logging.DebugLevel),
+		cache.NewStore(10, 1*time.Minute),
+		WithAuthenticator(authenticator),
+		WithRateLimiter(rateLimiter),
+	)
+
+	tests := []struct {
+		name   string
+		body   string
+		result string
+	}{
+		{
+			"batch calls and notifications charged to operation rate limit",
+			`[
+				{"jsonrpc":"2.0","method":"arithmetic.multiply","params":[2,3],"id":1},
+				{"jsonrpc":"2.0","method":"arithmetic.multiply","params":[2,3]},
+				{"jsonrpc":"2.0","method":"arithmetic.multiply","params":[2,3],"id":3}
+			]`,
+			`[
+				{"jsonrpc":"2.0","result":{"action":"multiply","x":2,"y":3,"answer":"6","cached":false},"id":1},
+				{"jsonrpc":"2.0","error":{"code":-32000,"message":"Limit exceeded","data":"rate limit exceeded, retry in 1000 seconds"},"id":3}
+			]`,
+		},
+		{
+			"batch calls charged to quota",
+			`[
+				{"jsonrpc":"2.0","method":"arithmetic.add","params":[1,2],"id":4},
+				{"jsonrpc":"2.0","method":"arithmetic.add","params":[1,2],"id":5},
+				{"jsonrpc":"2.0","method":"arithmetic.add","params":[1,2],"id":6}
+			]`,
+			`[
+				{"jsonrpc":"2.0","result":{"action":"add","x":1,"y":2,"answer":"3","cached":false},"id":4},
+				{"jsonrpc":"2.0","result":{"action":"add","x":1,"y":2,"answer":"3","cached":true},"id":5},
+				{"jsonrpc":"2.0","error":{"code":-32000,"message":"Limit exceeded","data":"daily quota exceeded"},"id":6}
+			]`,
+		},
+	}
+
+	for _, test := range tests {
+		w := httptest.NewRecorder()
+		req, err := http.NewRequest(http.MethodPost, RPCEndpoint, strings.NewReader(test.body))
+		assert.NoError(err, "Error should be nil")
+
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(APIKeyHeader, "partner")
+
+		r.ServeHTTP(w, req)
+		assert.Equal(http.StatusOK, w.Code, test.name)
+		assert.JSONEq(test.result, w.Body.String(), test.name)
+	}
+}
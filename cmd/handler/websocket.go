@@ -0,0 +1,262 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"regexp"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"github.com/pkg/errors"
+	"github.com/realmallaury/teltech/internal/arithmetic"
+	"github.com/realmallaury/teltech/internal/logging"
+)
+
+// WebSocket session limits.
+const (
+	// wsWriteWait is time allowed to write message to client.
+	wsWriteWait = 10 * time.Second
+
+	// wsPongWait is time allowed to read next pong message from client.
+	wsPongWait = 60 * time.Second
+
+	// wsPingPeriod is period of pings sent to client, must be less than wsPongWait.
+	wsPingPeriod = (wsPongWait * 9) / 10
+
+	// wsMaxMessageSize is maximum size of message from client in bytes.
+	wsMaxMessageSize int64 = 4096
+
+	// wsMaxPendingMessages is maximum number of responses waiting to be written,
+	// session is closed when client doesn't read responses fast enough.
+	wsMaxPendingMessages int = 16
+
+	// wsMaxVariables is maximum number of variables stored in session.
+	wsMaxVariables int = 100
+
+	// wsAnswerVariable references answer of previous operation.
+	wsAnswerVariable string = "ans"
+)
+
+var rxVariable = regexp.MustCompile("^[a-zA-Z_][a-zA-Z0-9_]*$")
+
+// WebSocketHandler serves calculator sessions over WebSocket connections,
+// sessions are closed when ctx is canceled.
+type WebSocketHandler struct {
+	ctx         context.Context
+	logger      *logging.Logger
+	calculator  *Calculator
+	rateLimiter *RateLimiter
+	upgrader    websocket.Upgrader
+}
+
+type wsRequest struct {
+	ID     json.RawMessage `json:"id,omitempty"`
+	Action string          `json:"action"`
+	X      json.RawMessage `json:"x"`
+	Y      json.RawMessage `json:"y"`
+	As     string          `json:"as,omitempty"`
+}
+
+type wsResponse struct {
+	ID     json.RawMessage    `json:"id,omitempty"`
+	Result *arithmetic.Result `json:"result,omitempty"`
+	Error  string             `json:"error,omitempty"`
+}
+
+type wsSession struct {
+	conn      *websocket.Conn
+	send      chan wsResponse
+	variables map[string]string
+}
+
+// NewWebSocketHandler creates WebSocket handler, each message takes rate limit token of endpoint same as
+// separate request and operation of message is charged to rate limit and API key quota by calculator.
+func NewWebSocketHandler(ctx context.Context, logger *logging.Logger, calculator *Calculator, rateLimiter *RateLimiter) *WebSocketHandler {
+	return &WebSocketHandler{
+		ctx:         ctx,
+		logger:      logger,
+		calculator:  calculator,
+		rateLimiter: rateLimiter,
+		upgrader: websocket.Upgrader{
+			ReadBufferSize:  1024,
+			WriteBufferSize: 1024,
+		},
+	}
+}
+
+// Serve upgrades connection and processes operations sent as JSON messages until connection is closed.
+// Operands can reference answer of previous operation with ans or variables stored with as field.
+func (wh *WebSocketHandler) Serve(c *gin.Context) {
+	conn, err := wh.upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		requestLogger(c, wh.logger).Warnf("WebSocket upgrade error: %v", err)
+		return
+	}
+
+	session := &wsSession{
+		conn:      conn,
+		send:      make(chan wsResponse, wsMaxPendingMessages),
+		variables: map[string]string{},
+	}
+
+	go wh.write(session)
+	wh.read(c, session)
+}
+
+// read processes client messages and queues responses, it closes send channel when done.
+func (wh *WebSocketHandler) read(c *gin.Context, session *wsSession) {
+	defer close(session.send)
+
+	session.conn.SetReadLimit(wsMaxMessageSize)
+	_ = session.conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	session.conn.SetPongHandler(func(string) error {
+		return session.conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	})
+
+	for {
+		_, message, err := session.conn.ReadMessage()
+		if err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseNormalClosure) {
+				requestLogger(c, wh.logger).Warnf("WebSocket read error: %v", err)
+			}
+			return
+		}
+
+		resp := wh.handle(c, session, message)
+
+		select {
+		case session.send <- resp:
+		default:
+			requestLogger(c, wh.logger).Warnf("WebSocket session closed, %d responses pending", len(session.send))
+			_ = session.conn.WriteControl(
+				websocket.CloseMessage,
+				websocket.FormatCloseMessage(websocket.ClosePolicyViolation, "too many pending messages"),
+				time.Now().Add(wsWriteWait),
+			)
+			return
+		}
+	}
+}
+
+// write sends queued responses and pings to client, closes connection when send channel
+// is closed, write fails or handler context is canceled.
+func (wh *WebSocketHandler) write(session *wsSession) {
+	ticker := time.NewTicker(wsPingPeriod)
+	defer func() {
+		ticker.Stop()
+		_ = session.conn.Close()
+	}()
+
+	for {
+		select {
+		case resp, ok := <-session.send:
+			_ = session.conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+			if !ok {
+				_ = session.conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+				return
+			}
+
+			if err := session.conn.WriteJSON(resp); err != nil {
+				return
+			}
+
+		case <-ticker.C:
+			_ = session.conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+			if err := session.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+
+		case <-wh.ctx.Done():
+			_ = session.conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+			_ = session.conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseGoingAway, "shutting down"))
+			return
+		}
+	}
+}
+
+// handle calculates result of single message and stores answer to session variables.
+func (wh *WebSocketHandler) handle(c *gin.Context, session *wsSession, message []byte) wsResponse {
+	var req wsRequest
+	if err := json.Unmarshal(message, &req); err != nil {
+		return wsResponse{Error: errors.Wrap(err, "invalid message").Error()}
+	}
+
+	resp := wsResponse{ID: req.ID}
+
+	if err := wh.limit(c); err != nil {
+		resp.Error = err.Error()
+		return resp
+	}
+
+	if req.As != "" && (!rxVariable.MatchString(req.As) || req.As == wsAnswerVariable) {
+		resp.Error = errors.Errorf("invalid variable name: %s", req.As).Error()
+		return resp
+	}
+
+	if _, ok := session.variables[req.As]; req.As != "" && !ok && len(session.variables) >= wsMaxVariables {
+		resp.Error = errors.Errorf("maximum number of variables: %d reached", wsMaxVariables).Error()
+		return resp
+	}
+
+	x, err := session.operand("x", req.X)
+	if err != nil {
+		resp.Error = err.Error()
+		return resp
+	}
+
+	y, err := session.operand("y", req.Y)
+	if err != nil {
+		resp.Error = err.Error()
+		return resp
+	}
+
+	result, err := wh.calculator.Calculate(c.Request.Context(), req.Action, x, y)
+	if err != nil {
+		requestLogger(c, wh.logger).Warnf("WebSocket %s message error: %v", req.Action, err)
+		resp.Error = err.Error()
+		return resp
+	}
+
+	session.variables[wsAnswerVariable] = result.Answer
+	if req.As != "" {
+		session.variables[req.As] = result.Answer
+	}
+
+	resp.Result = result
+	return resp
+}
+
+// limit takes rate limit token of client for message.
+func (wh *WebSocketHandler) limit(c *gin.Context) error {
+	result, err := wh.rateLimiter.Take(rateLimitClient(c), c.FullPath())
+	if err != nil {
+		// Backend failures should not block clients.
+		requestLogger(c, wh.logger).Errorf("WebSocket rate limiter error: %v", err)
+	}
+
+	if !result.Allowed {
+		return RateLimitError(result)
+	}
+
+	return nil
+}
+
+// operand returns operand value, operands matching variable name are replaced with variable value.
+func (s *wsSession) operand(name string, raw json.RawMessage) (string, error) {
+	value, err := jsonOperand(name, raw)
+	if err != nil {
+		return "", err
+	}
+
+	if !rxVariable.MatchString(value) {
+		return value, nil
+	}
+
+	variable, ok := s.variables[value]
+	if !ok {
+		return "", errors.Errorf("%s value: %s undefined variable", name, value)
+	}
+
+	return variable, nil
+}
@@ -0,0 +1,205 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"github.com/realmallaury/teltech/internal/auth"
+	"github.com/realmallaury/teltech/internal/cache"
+	"github.com/realmallaury/teltech/internal/logging"
+	"github.com/realmallaury/teltech/internal/ratelimit"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebSocket(t *testing.T) {
+	assert := assert.New(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	webSocketHandler := NewWebSocketHandler(
+		ctx,
+		logging.New(os.Stdout, logging.DebugLevel),
+		NewCalculator(logging.New(os.Stdout, logging.DebugLevel), cache.NewStore(10, 1*time.Minute)),
+		nil,
+	)
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET(WebSocketEndpoint, webSocketHandler.Serve)
+
+	server := httptest.NewServer(r)
+	defer server.Close()
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+WebSocketEndpoint, nil)
+	assert.NoError(err, "Error should be nil")
+	defer conn.Close()
+
+	tests := []struct {
+		message  string
+		response string
+	}{
+		{
+			`{"id":1,"action":"add","x":1,"y":"2"}`,
+			`{"id":1,"result":{"action":"add","x":1,"y":2,"answer":"3","cached":false}}`,
+		},
+		{
+			`{"id":2,"action":"multiply","x":"ans","y":4,"as":"total"}`,
+			`{"id":2,"result":{"action":"multiply","x":3,"y":4,"answer":"12","cached":false}}`,
+		},
+		{
+			`{"id":3,"action":"subtract","x":"total","y":"ans"}`,
+			`{"id":3,"result":{"action":"subtract","x":12,"y":12,"answer":"0","cached":false}}`,
+		},
+		{
+			`{"id":4,"action":"add","x":1,"y":"2"}`,
+			`{"id":4,"result":{"action":"add","x":1,"y":2,"answer":"3","cached":true}}`,
+		},
+		{
+			`{"id":5,"action":"divide","x":"unknown","y":2}`,
+			`{"id":5,"error":"x value: unknown undefined variable"}`,
+		},
+		{
+			`{"id":6,"action":"add","x":1,"y":2,"as":"ans"}`,
+			`{"id":6,"error":"invalid variable name: ans"}`,
+		},
+		{
+			`{"id":7,"action":"modulo","x":1,"y":2}`,
+			`{"id":7,"error":"unknown action: modulo"}`,
+		},
+		{
+			`not json`,
+			`{"error":"invalid message: invalid character 'o' in literal null (expecting 'u')"}`,
+		},
+	}
+
+	for _, test := range tests {
+		assert.NoError(conn.WriteMessage(websocket.TextMessage, []byte(test.message)))
+
+		_, message, err := conn.ReadMessage()
+		assert.NoError(err, "Error should be nil")
+		assert.Equal(test.response, strings.TrimSpace(string(message)), test.message)
+	}
+
+	// Test that too large message closes session
+	assert.NoError(conn.WriteMessage(websocket.TextMessage, make([]byte, wsMaxMessageSize+1)))
+
+	_, _, err = conn.ReadMessage()
+	assert.True(websocket.IsCloseError(err, websocket.CloseMessageTooBig), "Session should be closed")
+
+	// Test that sessions are closed on shutdown
+	conn, _, err = websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+WebSocketEndpoint, nil)
+	assert.NoError(err, "Error should be nil")
+	defer conn.Close()
+
+	cancel()
+
+	_, _, err = conn.ReadMessage()
+	assert.True(websocket.IsCloseError(err, websocket.CloseGoingAway), "Session should be closed")
+}
+
+func TestWebSocketLimits(t *testing.T) {
+	assert := assert.New(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	authenticator := NewAuthenticator(
+		auth.NewMemoryKeyStore([]auth.Key{
+			{Key: "limited", Name: "limited", Scopes: []string{auth.ScopeArithmeticRead}},
+			{Key: "quota", Name: "quota", Scopes: []string{auth.ScopeArithmeticRead}, DailyQuota: 2},
+		}),
+		auth.NewMemoryUsageStore(),
+		nil,
+	)
+
+	rateLimiter := NewRateLimiter(
+		ratelimit.NewMemoryBackend(1*time.Minute),
+		ratelimit.Limit{},
+		map[string]ratelimit.Limit{
+			WebSocketEndpoint: {Rate: 0.001, Burst: 4},
+			AddEndpoint:       {Rate: 0.001, Burst: 2},
+		},
+	)
+
+	webSocketHandler := NewWebSocketHandler(
+		ctx,
+		logging.New(os.Stdout, logging.DebugLevel),
+		NewCalculator(
+			logging.New(os.Stdout, logging.DebugLevel),
+			cache.NewStore(10, 1*time.Minute),
+			WithRateLimiter(rateLimiter),
+			WithAuthenticator(authenticator),
+		),
+		rateLimiter,
+	)
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET(
+		WebSocketEndpoint,
+		authenticator.Authenticate,
+		rateLimiter.Limit,
+		withCaller,
+		webSocketHandler.Serve,
+	)
+
+	server := httptest.NewServer(r)
+	defer server.Close()
+
+	tests := []struct {
+		apiKey    string
+		actions   []string
+		responses []string
+	}{
+		{
+			"limited",
+			[]string{"add", "add", "add", "subtract"},
+			[]string{
+				`{"id":1,"result":{"action":"add","x":1,"y":2,"answer":"3","cached":false}}`,
+				`{"id":2,"result":{"action":"add","x":1,"y":2,"answer":"3","cached":true}}`,
+				`{"id":3,"error":"rate limit exceeded, retry in 1000 seconds"}`,
+				`{"id":4,"error":"rate limit exceeded, retry in 1000 seconds"}`,
+			},
+		},
+		{
+			"quota",
+			[]string{"subtract", "subtract", "subtract"},
+			[]string{
+				`{"id":1,"result":{"action":"subtract","x":1,"y":2,"answer":"-1","cached":false}}`,
+				`{"id":2,"result":{"action":"subtract","x":1,"y":2,"answer":"-1","cached":true}}`,
+				`{"id":3,"error":"daily quota exceeded"}`,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		header := http.Header{}
+		header.Set(APIKeyHeader, test.apiKey)
+
+		// Upgrade request takes endpoint rate limit token too.
+		conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+WebSocketEndpoint, header)
+		if !assert.NoError(err, "Error should be nil") {
+			continue
+		}
+
+		for i, response := range test.responses {
+			request := `{"id":` + strconv.Itoa(i+1) + `,"action":"` + test.actions[i] + `","x":1,"y":2}`
+			assert.NoError(conn.WriteMessage(websocket.TextMessage, []byte(request)))
+
+			_, message, err := conn.ReadMessage()
+			assert.NoError(err, "Error should be nil")
+			assert.Equal(response, strings.TrimSpace(string(message)), test.apiKey)
+		}
+
+		conn.Close()
+	}
+}
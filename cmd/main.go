@@ -2,28 +2,64 @@ package main
 
 import (
 	"context"
+	"crypto/rsa"
+	"crypto/tls"
 	"log"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"path/filepath"
 	"strings"
+	"sync"
 	"syscall"
 	"time"
 
+	"github.com/realmallaury/teltech/cmd/grpcserver"
 	"github.com/realmallaury/teltech/cmd/handler"
+	"github.com/realmallaury/teltech/internal/auth"
 	"github.com/realmallaury/teltech/internal/cache"
+	"github.com/realmallaury/teltech/internal/logging"
+	"github.com/realmallaury/teltech/internal/ratelimit"
+	"github.com/realmallaury/teltech/internal/tlsutil"
+	"github.com/realmallaury/teltech/internal/tracing"
 
+	"github.com/fsnotify/fsnotify"
 	"github.com/pkg/errors"
 	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
+	"google.golang.org/grpc"
 )
 
 // Config stores app related configuration data.
 type Config struct {
 	Host            string
+	GRPCHost        string
 	ShutdownTimeout time.Duration
 	CacheSize       int
 	CacheTTL        time.Duration
+	ConfigFile      string
+	LogLevel        string
+	TraceExporter   string
+	TraceFile       string
+	TraceEndpoint   string
+	ServiceName     string
+	RateLimit       ratelimit.Limit
+	// EndpointRateLimits override RateLimit by endpoint path, set in config file only.
+	EndpointRateLimits map[string]ratelimit.Limit
+	Auth               bool
+	JWTHMACKeyFile     string
+	JWTRSAKeyFile      string
+	JWTAudience        string
+	TLSCertFile        string
+	TLSKeyFile         string
+	TLSClientCAFile    string
+	TLSClientAuth      string
+	HTTPRedirectHost   string
+	H2C                bool
+	DrainPeriod        time.Duration
+	// APIKeys are accepted API keys with quotas, set in config file only.
+	APIKeys []auth.Key
 }
 
 func main() {
@@ -34,16 +70,22 @@ func main() {
 }
 
 func run() error {
-	logger := log.New(os.Stdout, "Teltech : ", log.LstdFlags|log.Lmicroseconds|log.Lshortfile)
-
 	ctx, cancel := context.WithCancel(context.TODO())
 	defer cancel()
 
 	config := Config{
 		Host:            "0.0.0.0:8080",
+		GRPCHost:        "",
 		ShutdownTimeout: 5 * time.Second,
 		CacheSize:       1000,
 		CacheTTL:        1 * time.Minute,
+		LogLevel:        "info",
+		TraceExporter:   "none",
+		TraceFile:       "traces.json",
+		TraceEndpoint:   "http://localhost:4318/v1/traces",
+		ServiceName:     "arithmetic",
+		RateLimit:       ratelimit.Limit{Rate: 0, Burst: 20},
+		TLSClientAuth:   tlsutil.ClientAuthNone,
 	}
 
 	viper.AutomaticEnv()
@@ -51,9 +93,29 @@ func run() error {
 
 	f := pflag.NewFlagSet(os.Args[0], pflag.ContinueOnError)
 	f.String("host", config.Host, "the host and port of the CMS")
+	f.String("grpc-host", config.GRPCHost, "the host and port of gRPC server, empty disables gRPC")
 	f.Duration("shutdown-timeout", config.ShutdownTimeout, "server shutdown timeout")
 	f.Int("cache-size", config.CacheSize, "maximum cache size")
 	f.Duration("cache-ttl", config.CacheTTL, "cache ttl duration")
+	f.String("config", config.ConfigFile, "path to config file watched for changes")
+	f.String("log-level", config.LogLevel, "minimum log level: debug, info, warn or error")
+	f.String("trace-exporter", config.TraceExporter, "trace exporter: none, stdout, file or otlp")
+	f.String("trace-file", config.TraceFile, "file path used by file trace exporter")
+	f.String("trace-endpoint", config.TraceEndpoint, "OTLP/HTTP traces endpoint used by otlp trace exporter")
+	f.String("service-name", config.ServiceName, "service name reported in traces")
+	f.Float64("rate-limit", config.RateLimit.Rate, "requests per second allowed per client and endpoint, 0 disables rate limiting")
+	f.Int("rate-limit-burst", config.RateLimit.Burst, "maximum burst of requests per client and endpoint")
+	f.Bool("auth", config.Auth, "require API key configured in config file api-keys or JWT bearer token")
+	f.String("jwt-hmac-key-file", config.JWTHMACKeyFile, "file with HS256 secret used to validate bearer tokens")
+	f.String("jwt-rsa-key-file", config.JWTRSAKeyFile, "PEM file with RS256 public key used to validate bearer tokens")
+	f.String("jwt-audience", config.JWTAudience, "required bearer token audience")
+	f.String("tls-cert-file", config.TLSCertFile, "TLS certificate file, enables HTTPS, reloaded on change")
+	f.String("tls-key-file", config.TLSKeyFile, "TLS private key file, reloaded on change")
+	f.String("tls-client-ca-file", config.TLSClientCAFile, "CA certificates file used to verify client certificates")
+	f.String("tls-client-auth", config.TLSClientAuth, "client certificate verification: none, request or require")
+	f.String("http-redirect-host", config.HTTPRedirectHost, "the host and port of plain HTTP server redirecting to HTTPS")
+	f.Bool("h2c", config.H2C, "serve HTTP/2 over cleartext connections when TLS is not enabled")
+	f.Duration("drain-period", config.DrainPeriod, "duration to keep serving open connections after readiness fails on shutdown")
 
 	if err := f.Parse(os.Args[1:]); err != nil {
 		return err
@@ -63,48 +125,551 @@ func run() error {
 		return err
 	}
 
-	config.Host = viper.GetString("host")
-	config.ShutdownTimeout = viper.GetDuration("shutdown-timeout")
+	if configFile := viper.GetString("config"); configFile != "" {
+		viper.SetConfigFile(configFile)
+
+		if err := viper.ReadInConfig(); err != nil {
+			return errors.Wrap(err, "reading config file")
+		}
+	}
+
+	config, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	level, err := logging.ParseLevel(config.LogLevel)
+	if err != nil {
+		return err
+	}
 
-	logger.Printf("Config: %+v", config)
+	logger := logging.New(os.Stdout, level)
+	logger.Infof("Config: %+v", config.redacted())
 
 	store := cache.NewStore(config.CacheSize, config.CacheTTL)
 
+	tracer, err := newTracer(config, logger)
+	if err != nil {
+		return err
+	}
+
+	rateLimiter := handler.NewRateLimiter(
+		ratelimit.NewMemoryBackend(0),
+		config.RateLimit,
+		config.EndpointRateLimits,
+	)
+
+	keyStore := auth.NewMemoryKeyStore(config.APIKeys)
+
+	var authenticator *handler.Authenticator
+	if config.Auth {
+		jwtValidator, err := newJWTValidator(config)
+		if err != nil {
+			return err
+		}
+
+		authenticator = handler.NewAuthenticator(keyStore, auth.NewMemoryUsageStore(), jwtValidator)
+	}
+
+	// Settings which require restart, safe to read while config is reloaded.
+	startup := config.restartSettings()
+	host, grpcHost, shutdownTimeout, drainPeriod := startup.Host, startup.GRPCHost, startup.ShutdownTimeout, startup.DrainPeriod
+
+	tlsConfig, err := newTLSConfig(config, logger)
+	if err != nil {
+		return err
+	}
+
+	// reload re-reads the configuration and applies settings which can be changed without restarting
+	// the server, it is called only from the signal loop so viper is never read concurrently.
+	reload := func() {
+		if config.ConfigFile != "" {
+			if err := viper.ReadInConfig(); err != nil {
+				logger.Errorf("Config reload error: %v", err)
+				return
+			}
+		}
+
+		newConfig, err := loadConfig()
+		if err != nil {
+			logger.Errorf("Config reload error: %v", err)
+			return
+		}
+
+		if newConfig.restartSettings() != startup {
+			logger.Warnf("Config reload: host, gRPC host, shutdown timeout, drain period, TLS, HTTPS redirect, h2c, tracing and auth changes require restart")
+			newConfig.setRestartSettings(startup)
+		}
+
+		level, err := logging.ParseLevel(newConfig.LogLevel)
+		if err != nil {
+			logger.Errorf("Config reload error: %v", err)
+			return
+		}
+
+		// JWT key files are re-read, so rotated keys and audience are applied without restart.
+		if authenticator != nil {
+			jwtValidator, err := newJWTValidator(newConfig)
+			if err != nil {
+				logger.Errorf("Config reload error: %v", err)
+				return
+			}
+
+			authenticator.SetJWTValidator(jwtValidator)
+		}
+
+		logger.SetLevel(level)
+		store.SetLimits(newConfig.CacheSize, newConfig.CacheTTL)
+		rateLimiter.SetLimits(newConfig.RateLimit, newConfig.EndpointRateLimits)
+		keyStore.SetKeys(newConfig.APIKeys)
+		config = newConfig
+
+		logger.Infof("Config reloaded: %+v", config.redacted())
+	}
+
+	// Config file changes are only signaled by watcher and re-read in the signal loop.
+	configChanges := make(chan struct{}, 1)
+	if config.ConfigFile != "" {
+		if err := watchConfig(ctx, config.ConfigFile, configChanges, logger); err != nil {
+			return err
+		}
+	}
+
+	var inFlight handler.InFlightCounter
+
+	events := handler.NewEventBus()
+
+	// Requests are counted inside h2c handler, so streams of h2c connections are counted too.
+	apiHandler := inFlight.Wrap(handler.Router(
+		ctx,
+		logger,
+		store,
+		handler.WithTracer(tracer),
+		handler.WithRateLimiter(rateLimiter),
+		handler.WithAuthenticator(authenticator),
+		handler.WithEventBus(events),
+	))
+
+	// API server gets own copy of TLS config because Serve adds HTTP/2 protocols to it,
+	// while gRPC credentials read the shared config.
 	api := &http.Server{
-		Addr:    config.Host,
-		Handler: handler.Router(ctx, logger, store),
+		Addr:      host,
+		TLSConfig: tlsConfig.Clone(),
+		Handler:   apiHandler,
 	}
 
-	serverErrors := make(chan error, 1)
+	if startup.H2C && tlsConfig == nil {
+		if api.Handler, err = handler.H2C(api, apiHandler); err != nil {
+			return err
+		}
+	}
+
+	listener, err := net.Listen("tcp", host)
+	if err != nil {
+		return errors.Wrap(err, "starting server")
+	}
+
+	serverErrors := make(chan error, 3)
 
 	go func() {
-		logger.Printf("API Listening on %s", config.Host)
-		serverErrors <- api.ListenAndServe()
+		if tlsConfig != nil {
+			logger.Infof("API Listening with TLS on %s", host)
+			serverErrors <- api.ServeTLS(listener, "", "")
+			return
+		}
+
+		logger.Infof("API Listening on %s", host)
+		serverErrors <- api.Serve(listener)
 	}()
 
+	var redirect *http.Server
+	if tlsConfig != nil && startup.HTTPRedirectHost != "" {
+		redirect = &http.Server{
+			Addr:    startup.HTTPRedirectHost,
+			Handler: handler.HTTPSRedirect(host),
+		}
+
+		go func() {
+			logger.Infof("HTTPS redirect Listening on %s", redirect.Addr)
+			serverErrors <- redirect.ListenAndServe()
+		}()
+	}
+
+	var grpcServer *grpc.Server
+	if grpcHost != "" {
+		grpcListener, err := net.Listen("tcp", grpcHost)
+		if err != nil {
+			return errors.Wrap(err, "starting gRPC server")
+		}
+
+		grpcServer = grpcserver.New(
+			logger,
+			handler.NewCalculator(
+				logger,
+				store,
+				handler.WithTracer(tracer),
+				handler.WithEventBus(events),
+				handler.WithRateLimiter(rateLimiter),
+				handler.WithAuthenticator(authenticator),
+			),
+			grpcserver.WithAuthenticator(authenticator),
+			grpcserver.WithRateLimiter(rateLimiter),
+			grpcserver.WithTLSConfig(tlsConfig),
+		)
+
+		go func() {
+			logger.Infof("gRPC Listening on %s", grpcHost)
+			serverErrors <- grpcServer.Serve(grpcListener)
+		}()
+	}
+
 	osSignals := make(chan os.Signal, 1)
-	signal.Notify(osSignals, os.Interrupt, syscall.SIGTERM)
+	signal.Notify(osSignals, os.Interrupt, syscall.SIGTERM, syscall.SIGHUP)
+
+	for {
+		select {
+		case err := <-serverErrors:
+			return errors.Wrap(err, "starting server")
+
+		case <-configChanges:
+			logger.Infof("Config file changed, reloading config...")
+			reload()
+
+		case sig := <-osSignals:
+			if sig == syscall.SIGHUP {
+				logger.Infof("Reloading config...")
+				reload()
+				continue
+			}
+
+			logger.Infof("Start shutdown...")
+
+			// Stop accepting new connections, open connections are served until shutdown
+			// without keep alive so clients reconnect to other instances.
+			_ = listener.Close()
+			api.SetKeepAlivesEnabled(false)
+
+			// Cancel router context so readiness probe fails while shutting down.
+			cancel()
+
+			if drainPeriod > 0 {
+				logger.Infof("Draining connections for %v, %d requests in flight", drainPeriod, inFlight.Count())
+				time.Sleep(drainPeriod)
+			}
+
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+			defer cancel()
+
+			if redirect != nil {
+				if err := redirect.Shutdown(shutdownCtx); err != nil {
+					logger.Errorf("HTTPS redirect shutdown error: %v", err)
+				}
+			}
+
+			// gRPC server is stopped concurrently with API server, so both have full shutdown timeout.
+			var grpcStopped sync.WaitGroup
+			if grpcServer != nil {
+				grpcStopped.Add(1)
+				go func() {
+					defer grpcStopped.Done()
+					stopGRPC(shutdownCtx, grpcServer, logger)
+				}()
+			}
+
+			// Shutdown doesn't wait for requests of hijacked h2c connections, they are waited for separately.
+			err := api.Shutdown(shutdownCtx)
+			if err == nil {
+				err = inFlight.Wait(shutdownCtx)
+			}
+			grpcStopped.Wait()
+			if err != nil {
+				logger.Errorf(
+					"Graceful shutdown did not complete in %v : %v, %d in-flight requests cut off",
+					shutdownTimeout, err, inFlight.Count(),
+				)
+				err = api.Close()
+			}
+
+			if err != nil {
+				return errors.Wrap(err, "could not stop server gracefully")
+			}
+
+			if err := tracer.Shutdown(shutdownCtx); err != nil {
+				logger.Errorf("Tracer shutdown error: %v", err)
+			}
+
+			return nil
+		}
+	}
+}
+
+// stopGRPC gracefully stops gRPC server, pending calls are cancelled when context is done.
+func stopGRPC(ctx context.Context, server *grpc.Server, logger *logging.Logger) {
+	stopped := make(chan struct{})
+	go func() {
+		server.GracefulStop()
+		close(stopped)
+	}()
 
 	select {
-	case err := <-serverErrors:
-		return errors.Wrap(err, "starting server")
+	case <-stopped:
+	case <-ctx.Done():
+		logger.Errorf("gRPC graceful shutdown did not complete: %v", ctx.Err())
+		server.Stop()
+	}
+}
+
+// configDebounce is period without config file events after which change is signaled.
+const configDebounce = 100 * time.Millisecond
+
+// watchConfig notifies changes of config file until ctx is canceled, directory of the file is watched
+// so files replaced by editors or mounted config maps are noticed too.
+func watchConfig(ctx context.Context, path string, changes chan<- struct{}, logger *logging.Logger) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return errors.Wrap(err, "watching config file")
+	}
+
+	file := filepath.Clean(path)
+	realFile, _ := filepath.EvalSymlinks(file)
+
+	if err := watcher.Add(filepath.Dir(file)); err != nil {
+		_ = watcher.Close()
+		return errors.Wrap(err, "watching config file")
+	}
+
+	go func() {
+		defer watcher.Close()
+
+		// Change is signaled when file events stop for debounce period, so file truncated and written
+		// in separate steps is not read half written.
+		debounce := time.NewTimer(time.Hour)
+		debounce.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+
+			case <-debounce.C:
+				// Pending change is not queued twice, the file is read once for both.
+				select {
+				case changes <- struct{}{}:
+				default:
+				}
+
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
 
-	case <-osSignals:
-		logger.Println("Start shutdown...")
+				// File is changed when it is written or created, or when symlink points to new file.
+				currentFile, _ := filepath.EvalSymlinks(file)
+				if filepath.Clean(event.Name) == file && event.Op&(fsnotify.Write|fsnotify.Create) != 0 ||
+					currentFile != "" && currentFile != realFile {
+					realFile = currentFile
+
+					debounce.Stop()
+					debounce.Reset(configDebounce)
+				}
+
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+
+				logger.Errorf("Config watch error: %v", err)
+			}
+		}
+	}()
 
-		shutdownCtx, cancel := context.WithTimeout(context.Background(), config.ShutdownTimeout)
-		defer cancel()
+	return nil
+}
+
+// restartSettings are settings applied on start only, changes on config reload are ignored.
+type restartSettings struct {
+	Host             string
+	GRPCHost         string
+	ShutdownTimeout  time.Duration
+	DrainPeriod      time.Duration
+	TLSCertFile      string
+	TLSKeyFile       string
+	TLSClientCAFile  string
+	TLSClientAuth    string
+	HTTPRedirectHost string
+	H2C              bool
+	TraceExporter    string
+	TraceFile        string
+	TraceEndpoint    string
+	ServiceName      string
+	Auth             bool
+}
+
+// restartSettings returns settings of config which require restart.
+func (c Config) restartSettings() restartSettings {
+	return restartSettings{
+		Host:             c.Host,
+		GRPCHost:         c.GRPCHost,
+		ShutdownTimeout:  c.ShutdownTimeout,
+		DrainPeriod:      c.DrainPeriod,
+		TLSCertFile:      c.TLSCertFile,
+		TLSKeyFile:       c.TLSKeyFile,
+		TLSClientCAFile:  c.TLSClientCAFile,
+		TLSClientAuth:    c.TLSClientAuth,
+		HTTPRedirectHost: c.HTTPRedirectHost,
+		H2C:              c.H2C,
+		TraceExporter:    c.TraceExporter,
+		TraceFile:        c.TraceFile,
+		TraceEndpoint:    c.TraceEndpoint,
+		ServiceName:      c.ServiceName,
+		Auth:             c.Auth,
+	}
+}
+
+// setRestartSettings overrides settings of config which require restart.
+func (c *Config) setRestartSettings(s restartSettings) {
+	c.Host = s.Host
+	c.GRPCHost = s.GRPCHost
+	c.ShutdownTimeout = s.ShutdownTimeout
+	c.DrainPeriod = s.DrainPeriod
+	c.TLSCertFile = s.TLSCertFile
+	c.TLSKeyFile = s.TLSKeyFile
+	c.TLSClientCAFile = s.TLSClientCAFile
+	c.TLSClientAuth = s.TLSClientAuth
+	c.HTTPRedirectHost = s.HTTPRedirectHost
+	c.H2C = s.H2C
+	c.TraceExporter = s.TraceExporter
+	c.TraceFile = s.TraceFile
+	c.TraceEndpoint = s.TraceEndpoint
+	c.ServiceName = s.ServiceName
+	c.Auth = s.Auth
+}
 
-		err := api.Shutdown(shutdownCtx)
+// redacted returns copy of config without API key secrets, used for logging.
+func (c Config) redacted() Config {
+	keys := make([]auth.Key, len(c.APIKeys))
+	for i, key := range c.APIKeys {
+		key.Key = "***"
+		keys[i] = key
+	}
+
+	c.APIKeys = keys
+	return c
+}
+
+// loadConfig reads current configuration values from viper.
+func loadConfig() (Config, error) {
+	config := Config{
+		Host:            viper.GetString("host"),
+		GRPCHost:        viper.GetString("grpc-host"),
+		ShutdownTimeout: viper.GetDuration("shutdown-timeout"),
+		CacheSize:       viper.GetInt("cache-size"),
+		CacheTTL:        viper.GetDuration("cache-ttl"),
+		ConfigFile:      viper.GetString("config"),
+		LogLevel:        viper.GetString("log-level"),
+		TraceExporter:   viper.GetString("trace-exporter"),
+		TraceFile:       viper.GetString("trace-file"),
+		TraceEndpoint:   viper.GetString("trace-endpoint"),
+		ServiceName:     viper.GetString("service-name"),
+		RateLimit: ratelimit.Limit{
+			Rate:  viper.GetFloat64("rate-limit"),
+			Burst: viper.GetInt("rate-limit-burst"),
+		},
+		Auth:             viper.GetBool("auth"),
+		JWTHMACKeyFile:   viper.GetString("jwt-hmac-key-file"),
+		JWTRSAKeyFile:    viper.GetString("jwt-rsa-key-file"),
+		JWTAudience:      viper.GetString("jwt-audience"),
+		TLSCertFile:      viper.GetString("tls-cert-file"),
+		TLSKeyFile:       viper.GetString("tls-key-file"),
+		TLSClientCAFile:  viper.GetString("tls-client-ca-file"),
+		TLSClientAuth:    viper.GetString("tls-client-auth"),
+		HTTPRedirectHost: viper.GetString("http-redirect-host"),
+		H2C:              viper.GetBool("h2c"),
+		DrainPeriod:      viper.GetDuration("drain-period"),
+	}
+
+	if err := viper.UnmarshalKey("rate-limits", &config.EndpointRateLimits); err != nil {
+		return config, errors.Wrap(err, "reading endpoint rate limits")
+	}
+
+	if err := viper.UnmarshalKey("api-keys", &config.APIKeys); err != nil {
+		return config, errors.Wrap(err, "reading API keys")
+	}
+
+	return config, nil
+}
+
+// newTracer creates tracer with configured exporter, returns nil tracer when tracing is disabled.
+func newTracer(config Config, logger *logging.Logger) (*tracing.Tracer, error) {
+	onError := func(err error) {
+		logger.Errorf("Trace export error: %v", err)
+	}
+
+	switch config.TraceExporter {
+	case "", "none":
+		return nil, nil
+
+	case "stdout":
+		return tracing.New(tracing.NewWriterExporter(os.Stdout), onError), nil
+
+	case "file":
+		exporter, err := tracing.NewFileExporter(config.TraceFile)
 		if err != nil {
-			log.Printf("main : Graceful shutdown did not complete in %v : %v", config.ShutdownTimeout, err)
-			err = api.Close()
+			return nil, err
 		}
 
+		return tracing.New(exporter, onError), nil
+
+	case "otlp":
+		return tracing.New(tracing.NewOTLPExporter(config.TraceEndpoint, config.ServiceName, onError), onError), nil
+	}
+
+	return nil, errors.Errorf("unknown trace exporter: %s", config.TraceExporter)
+}
+
+// newJWTValidator creates bearer token validator with keys loaded from files,
+// returns nil validator when no key file is configured.
+func newJWTValidator(config Config) (*auth.JWTValidator, error) {
+	if config.JWTHMACKeyFile == "" && config.JWTRSAKeyFile == "" {
+		return nil, nil
+	}
+
+	var hmacKey []byte
+	if config.JWTHMACKeyFile != "" {
+		key, err := auth.LoadHMACKey(config.JWTHMACKeyFile)
 		if err != nil {
-			return errors.Wrap(err, "could not stop server gracefully")
+			return nil, err
 		}
+
+		hmacKey = key
 	}
 
-	return nil
+	var rsaKey *rsa.PublicKey
+	if config.JWTRSAKeyFile != "" {
+		key, err := auth.LoadRSAPublicKey(config.JWTRSAKeyFile)
+		if err != nil {
+			return nil, err
+		}
+
+		rsaKey = key
+	}
+
+	return auth.NewJWTValidator(hmacKey, rsaKey, config.JWTAudience), nil
+}
+
+// newTLSConfig creates server TLS config with certificate reloaded on file change,
+// returns nil config when no certificate file is configured.
+func newTLSConfig(config Config, logger *logging.Logger) (*tls.Config, error) {
+	if config.TLSCertFile == "" {
+		return nil, nil
+	}
+
+	reloader, err := tlsutil.NewCertReloader(config.TLSCertFile, config.TLSKeyFile, func(err error) {
+		logger.Errorf("TLS certificate reload error: %v", err)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return tlsutil.NewServerConfig(reloader, config.TLSClientCAFile, config.TLSClientAuth)
 }
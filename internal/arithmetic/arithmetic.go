@@ -1,6 +1,9 @@
 package arithmetic
 
 import (
+	"encoding/xml"
+	"math/big"
+
 	"github.com/pkg/errors"
 	"github.com/realmallaury/teltech/internal/utils"
 )
@@ -15,11 +18,178 @@ const (
 
 // Result contains data asociated with arithmetic operation.
 type Result struct {
-	Action string  `json:"action"`
-	X      float64 `json:"x"`
-	Y      float64 `json:"y"`
-	Answer string  `json:"answer"`
-	Cached bool    `json:"cached"`
+	XMLName xml.Name `json:"-" xml:"result"`
+	Action  string   `json:"action" xml:"action"`
+	X       float64  `json:"x" xml:"x"`
+	Y       float64  `json:"y" xml:"y"`
+	Answer  string   `json:"answer" xml:"answer"`
+	// Numerator, Denominator and Decimal approximation are set by rational arithmetic.
+	Numerator   string `json:"numerator,omitempty" xml:"numerator,omitempty"`
+	Denominator string `json:"denominator,omitempty" xml:"denominator,omitempty"`
+	Decimal     string `json:"decimal,omitempty" xml:"decimal,omitempty"`
+	Cached      bool   `json:"cached" xml:"cached"`
+	// XExact and YExact are exact operands of integer and rational arithmetic, X and Y are their
+	// float approximations. They are not serialized, API versions with string operands use them.
+	XExact string `json:"-" xml:"-"`
+	YExact string `json:"-" xml:"-"`
+}
+
+// Operation converts x and y to float and returns result of arithmetic operation.
+type Operation func(x, y string) (*Result, error)
+
+// Operations are arithmetic operations by action name.
+var Operations = map[string]Operation{
+	AddConst:      Add,
+	SubtractConst: Subtract,
+	MultiplyConst: Multiply,
+	DivideConst:   Divide,
+}
+
+// IntOperation returns result of integer arithmetic operation.
+type IntOperation func(x, y *big.Int) (*big.Int, error)
+
+// IntOperations are integer arithmetic operations by action name.
+var IntOperations = map[string]IntOperation{
+	AddConst: func(x, y *big.Int) (*big.Int, error) {
+		return new(big.Int).Add(x, y), nil
+	},
+	SubtractConst: func(x, y *big.Int) (*big.Int, error) {
+		return new(big.Int).Sub(x, y), nil
+	},
+	MultiplyConst: func(x, y *big.Int) (*big.Int, error) {
+		return new(big.Int).Mul(x, y), nil
+	},
+	DivideConst: func(x, y *big.Int) (*big.Int, error) {
+		if y.Sign() == 0 {
+			return nil, errors.New("integer division by zero")
+		}
+
+		return new(big.Int).Quo(x, y), nil
+	},
+}
+
+// RatOperation returns result of rational arithmetic operation.
+type RatOperation func(x, y *big.Rat) (*big.Rat, error)
+
+// RatOperations are rational arithmetic operations by action name.
+var RatOperations = map[string]RatOperation{
+	AddConst: func(x, y *big.Rat) (*big.Rat, error) {
+		return new(big.Rat).Add(x, y), nil
+	},
+	SubtractConst: func(x, y *big.Rat) (*big.Rat, error) {
+		return new(big.Rat).Sub(x, y), nil
+	},
+	MultiplyConst: func(x, y *big.Rat) (*big.Rat, error) {
+		return new(big.Rat).Mul(x, y), nil
+	},
+	DivideConst: func(x, y *big.Rat) (*big.Rat, error) {
+		if y.Sign() == 0 {
+			return nil, errors.New("rational division by zero")
+		}
+
+		return new(big.Rat).Quo(x, y), nil
+	},
+}
+
+// OperationInfo describes arithmetic operation.
+type OperationInfo struct {
+	Name        string `json:"name"`
+	Arity       int    `json:"arity"`
+	Description string `json:"description"`
+}
+
+// OperationInfos describe available arithmetic operations.
+var OperationInfos = []OperationInfo{
+	{Name: AddConst, Arity: 2, Description: "Returns sum of x and y."},
+	{Name: SubtractConst, Arity: 2, Description: "Returns difference of x and y."},
+	{Name: MultiplyConst, Arity: 2, Description: "Returns product of x and y."},
+	{Name: DivideConst, Arity: 2, Description: "Returns quotient of x and y, float division by zero returns +Inf or -Inf."},
+}
+
+// Calculate returns result of arithmetic operation with action name,
+// operands with 0x, 0o or 0b prefix are calculated with integer arithmetic
+// and fraction operands are calculated with rational arithmetic.
+func Calculate(action, x, y string) (*Result, error) {
+	operation, ok := Operations[action]
+	if !ok {
+		return nil, errors.Errorf("unknown action: %s", action)
+	}
+
+	if utils.IsRadixInt(x) || utils.IsRadixInt(y) {
+		return CalculateInt(action, x, y)
+	}
+
+	if utils.IsFraction(x) || utils.IsFraction(y) {
+		return CalculateRat(action, x, y)
+	}
+
+	return operation(x, y)
+}
+
+// CalculateInt converts x and y to integers and returns result of integer arithmetic operation
+// with decimal answer.
+func CalculateInt(action, x, y string) (*Result, error) {
+	operation, ok := IntOperations[action]
+	if !ok {
+		return nil, errors.Errorf("unknown action: %s", action)
+	}
+
+	xVal, yVal, err := utils.ConvertInt(x, y)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s values: %v and %v", action, x, y)
+	}
+
+	answer, err := operation(xVal, yVal)
+	if err != nil {
+		return nil, err
+	}
+
+	xFloat, _ := new(big.Float).SetInt(xVal).Float64()
+	yFloat, _ := new(big.Float).SetInt(yVal).Float64()
+
+	return &Result{
+		Action: action,
+		X:      xFloat,
+		Y:      yFloat,
+		Answer: answer.String(),
+		XExact: xVal.String(),
+		YExact: yVal.String(),
+	}, nil
+}
+
+// CalculateRat converts x and y to rational numbers and returns exact result of rational arithmetic operation,
+// answer is fraction in lowest terms, or integer when denominator is 1.
+func CalculateRat(action, x, y string) (*Result, error) {
+	operation, ok := RatOperations[action]
+	if !ok {
+		return nil, errors.Errorf("unknown action: %s", action)
+	}
+
+	xVal, yVal, err := utils.ConvertRat(x, y)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s values: %v and %v", action, x, y)
+	}
+
+	answer, err := operation(xVal, yVal)
+	if err != nil {
+		return nil, err
+	}
+
+	xFloat, _ := xVal.Float64()
+	yFloat, _ := yVal.Float64()
+	decimal, _ := answer.Float64()
+
+	return &Result{
+		Action:      action,
+		X:           xFloat,
+		Y:           yFloat,
+		Answer:      answer.RatString(),
+		Numerator:   answer.Num().String(),
+		Denominator: answer.Denom().String(),
+		Decimal:     utils.FloatToString(decimal),
+		XExact:      xVal.RatString(),
+		YExact:      yVal.RatString(),
+	}, nil
 }
 
 // Add converts x and y to float and return their addition.
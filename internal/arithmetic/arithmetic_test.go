@@ -220,3 +220,104 @@ func TestDivide(t *testing.T) {
 		}
 	}
 }
+
+func TestCalculate(t *testing.T) {
+	assert := assert.New(t)
+
+	tables := []struct {
+		action  string
+		x       string
+		y       string
+		res     *Result
+		errFlag bool
+	}{
+		{AddConst, "1", "1", &Result{Action: AddConst, X: 1, Y: 1, Answer: "2", Cached: false}, false},
+		{SubtractConst, "1", "1", &Result{Action: SubtractConst, X: 1, Y: 1, Answer: "0", Cached: false}, false},
+		{MultiplyConst, "2", "2", &Result{Action: MultiplyConst, X: 2, Y: 2, Answer: "4", Cached: false}, false},
+		{DivideConst, "2", "2", &Result{Action: DivideConst, X: 2, Y: 2, Answer: "1", Cached: false}, false},
+		{"modulo", "2", "2", nil, true},
+		{AddConst, "1", "1..", nil, true},
+		{AddConst, "0xff", "1", &Result{Action: AddConst, X: 255, Y: 1, Answer: "256", XExact: "255", YExact: "1"}, false},
+		{SubtractConst, "0b1010", "0o17", &Result{Action: SubtractConst, X: 10, Y: 15, Answer: "-5", XExact: "10", YExact: "15"}, false},
+		{
+			MultiplyConst,
+			"0xffffffffffffffff",
+			"0x10",
+			&Result{
+				Action: MultiplyConst,
+				X:      18446744073709551615,
+				Y:      16,
+				Answer: "295147905179352825840",
+				XExact: "18446744073709551615",
+				YExact: "16",
+			},
+			false,
+		},
+		{DivideConst, "-0x7", "2", &Result{Action: DivideConst, X: -7, Y: 2, Answer: "-3", XExact: "-7", YExact: "2"}, false},
+		{DivideConst, "0x7", "0", nil, true},
+		{AddConst, "0x7", "1.5", nil, true},
+		{
+			AddConst,
+			"1/3",
+			"1/6",
+			&Result{Action: AddConst, X: 1.0 / 3, Y: 1.0 / 6, Answer: "1/2", Numerator: "1", Denominator: "2", Decimal: "0.5", XExact: "1/3", YExact: "1/6"},
+			false,
+		},
+		{
+			SubtractConst,
+			"0.1",
+			"-1/10",
+			&Result{Action: SubtractConst, X: 0.1, Y: -0.1, Answer: "1/5", Numerator: "1", Denominator: "5", Decimal: "0.2", XExact: "1/10", YExact: "-1/10"},
+			false,
+		},
+		{
+			MultiplyConst,
+			"2/3",
+			"3",
+			&Result{Action: MultiplyConst, X: 2.0 / 3, Y: 3, Answer: "2", Numerator: "2", Denominator: "1", Decimal: "2", XExact: "2/3", YExact: "3"},
+			false,
+		},
+		{
+			DivideConst,
+			"1/3",
+			"7",
+			&Result{
+				Action:      DivideConst,
+				X:           1.0 / 3,
+				Y:           7,
+				Answer:      "1/21",
+				Numerator:   "1",
+				Denominator: "21",
+				Decimal:     "0.047619047619047616",
+				XExact:      "1/3",
+				YExact:      "7",
+			},
+			false,
+		},
+		{DivideConst, "1/3", "0", nil, true},
+		{AddConst, "1/3", "1e400", nil, true},
+	}
+
+	for _, table := range tables {
+		res, err := Calculate(table.action, table.x, table.y)
+
+		assert.Equal(table.res, res, "Values should be the same")
+
+		if table.errFlag {
+			assert.Error(err, "Should be error")
+		} else {
+			assert.NoError(err, "Error should be nil")
+		}
+	}
+}
+
+func TestOperationInfos(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Len(OperationInfos, len(Operations), "All operations should be described")
+
+	for _, info := range OperationInfos {
+		_, ok := Operations[info.Name]
+		assert.True(ok, "Described operation should exist: %s", info.Name)
+	}
+}
@@ -0,0 +1,297 @@
+package arithmetic
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/realmallaury/teltech/internal/utils"
+)
+
+// Expression limits.
+const (
+	// MaxExpressionLength is maximum length of expression in bytes.
+	MaxExpressionLength int = 4096
+	// MaxExpressionOperations is maximum number of arithmetic operations in expression.
+	MaxExpressionOperations int = 100
+)
+
+// ExpressionResult contains answer of evaluated expression.
+type ExpressionResult struct {
+	Expression string `json:"expression" xml:"expression"`
+	Answer     string `json:"answer" xml:"answer"`
+	// Cached is true when all operations of expression were served from cache.
+	Cached bool `json:"cached" xml:"cached"`
+}
+
+// CalculateFunc returns result of arithmetic operation with action name, e.g. cached Calculate.
+type CalculateFunc func(action, x, y string) (*Result, error)
+
+// Evaluate evaluates expression with +, -, *, / operators, unary signs and parentheses,
+// e.g. (1.5 + 0x10) * -2. Each binary operation is computed with calculate, so operands and answers
+// follow the same float, integer and rational rules as single operations.
+func Evaluate(expression string, calculate CalculateFunc) (*ExpressionResult, error) {
+	if len(expression) > MaxExpressionLength {
+		return nil, errors.Errorf("expression length: %d exceeds maximum: %d", len(expression), MaxExpressionLength)
+	}
+
+	tokens, err := tokenize(expression)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(tokens) == 0 {
+		return nil, errors.New("empty expression")
+	}
+
+	p := &parser{tokens: tokens, calculate: calculate, cached: true}
+
+	answer, err := p.expression()
+	if err != nil {
+		return nil, err
+	}
+
+	if p.pos < len(p.tokens) {
+		return nil, errors.Errorf("unexpected %s at position %d", p.tokens[p.pos].text, p.tokens[p.pos].pos)
+	}
+
+	// Single number is validated same as operands of operations.
+	if p.operations == 0 {
+		if ok, err := utils.IsXYValid(answer, "0"); !ok {
+			return nil, err
+		}
+	}
+
+	// Integer answers are carried as prefixed operands and returned in decimal same as single operations.
+	if utils.IsRadixInt(answer) {
+		value, err := utils.ParseInt(answer)
+		if err != nil {
+			return nil, err
+		}
+
+		answer = value.String()
+	}
+
+	return &ExpressionResult{Expression: expression, Answer: answer, Cached: p.cached && p.operations > 0}, nil
+}
+
+// operators are binary operator actions.
+var operators = map[string]string{
+	"+": AddConst,
+	"-": SubtractConst,
+	"*": MultiplyConst,
+	"/": DivideConst,
+}
+
+type token struct {
+	text string
+	// pos is 1-based position of token in expression.
+	pos     int
+	operand bool
+}
+
+// tokenize splits expression to numbers, operators and parentheses.
+func tokenize(expression string) ([]token, error) {
+	var tokens []token
+
+	for i := 0; i < len(expression); {
+		ch := expression[i]
+
+		switch {
+		case ch == ' ' || ch == '\t' || ch == '\n' || ch == '\r':
+			i++
+
+		case strings.IndexByte("+-*/()", ch) >= 0:
+			tokens = append(tokens, token{text: string(ch), pos: i + 1})
+			i++
+
+		case ch >= '0' && ch <= '9' || ch == '.':
+			end := numberEnd(expression, i)
+			tokens = append(tokens, token{text: expression[i:end], pos: i + 1, operand: true})
+			i = end
+
+		default:
+			return nil, errors.Errorf("unexpected character %q at position %d", ch, i+1)
+		}
+	}
+
+	return tokens, nil
+}
+
+// numberEnd returns end of number starting at i, prefixed integers are read to the end of
+// alphanumeric characters and decimal numbers may have exponent, e.g. 1.5e-3.
+func numberEnd(s string, i int) int {
+	if len(s) > i+1 && s[i] == '0' && strings.IndexByte("xXoObB", s[i+1]) >= 0 {
+		j := i + 2
+		for j < len(s) && isAlphanumeric(s[j]) {
+			j++
+		}
+
+		return j
+	}
+
+	j := i
+	for j < len(s) && (s[j] >= '0' && s[j] <= '9' || s[j] == '.') {
+		j++
+	}
+
+	if j < len(s) && (s[j] == 'e' || s[j] == 'E') {
+		k := j + 1
+		if k < len(s) && (s[k] == '+' || s[k] == '-') {
+			k++
+		}
+
+		if k < len(s) && s[k] >= '0' && s[k] <= '9' {
+			for k < len(s) && s[k] >= '0' && s[k] <= '9' {
+				k++
+			}
+
+			j = k
+		}
+	}
+
+	return j
+}
+
+func isAlphanumeric(ch byte) bool {
+	return ch >= '0' && ch <= '9' || ch >= 'a' && ch <= 'z' || ch >= 'A' && ch <= 'Z'
+}
+
+// parser is recursive descent parser evaluating expression while parsing.
+type parser struct {
+	tokens     []token
+	pos        int
+	operations int
+	calculate  CalculateFunc
+	cached     bool
+}
+
+// expression = term { ("+" | "-") term }.
+func (p *parser) expression() (string, error) {
+	x, err := p.term()
+	if err != nil {
+		return "", err
+	}
+
+	for p.peek("+") || p.peek("-") {
+		op := p.next()
+
+		y, err := p.term()
+		if err != nil {
+			return "", err
+		}
+
+		if x, err = p.apply(op, x, y); err != nil {
+			return "", err
+		}
+	}
+
+	return x, nil
+}
+
+// term = factor { ("*" | "/") factor }.
+func (p *parser) term() (string, error) {
+	x, err := p.factor()
+	if err != nil {
+		return "", err
+	}
+
+	for p.peek("*") || p.peek("/") {
+		op := p.next()
+
+		y, err := p.factor()
+		if err != nil {
+			return "", err
+		}
+
+		if x, err = p.apply(op, x, y); err != nil {
+			return "", err
+		}
+	}
+
+	return x, nil
+}
+
+// factor = ("+" | "-") factor | number | "(" expression ")".
+func (p *parser) factor() (string, error) {
+	if p.pos >= len(p.tokens) {
+		return "", errors.New("unexpected end of expression")
+	}
+
+	t := p.next()
+
+	switch {
+	case t.text == "+" || t.text == "-":
+		value, err := p.factor()
+		if err != nil {
+			return "", err
+		}
+
+		if t.text == "-" {
+			return negate(value), nil
+		}
+
+		return value, nil
+
+	case t.operand:
+		return t.text, nil
+
+	case t.text == "(":
+		value, err := p.expression()
+		if err != nil {
+			return "", err
+		}
+
+		if !p.peek(")") {
+			return "", errors.Errorf("missing closing parenthesis for parenthesis at position %d", t.pos)
+		}
+
+		p.next()
+		return value, nil
+	}
+
+	return "", errors.Errorf("unexpected %s at position %d", t.text, t.pos)
+}
+
+func (p *parser) apply(op token, x, y string) (string, error) {
+	p.operations++
+	if p.operations > MaxExpressionOperations {
+		return "", errors.Errorf("expression exceeds maximum of %d operations", MaxExpressionOperations)
+	}
+
+	result, err := p.calculate(operators[op.text], x, y)
+	if err != nil {
+		return "", errors.Wrapf(err, "operator %s at position %d", op.text, op.pos)
+	}
+
+	p.cached = p.cached && result.Cached
+
+	// Answer of integer operation stays integer operand, so integer arithmetic applies to whole expression.
+	if utils.IsRadixInt(x) || utils.IsRadixInt(y) {
+		answer, _, err := utils.IntegerXY(result.Answer, "0")
+		return answer, err
+	}
+
+	return result.Answer, nil
+}
+
+func (p *parser) peek(text string) bool {
+	return p.pos < len(p.tokens) && p.tokens[p.pos].text == text
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	p.pos++
+	return t
+}
+
+// negate changes sign of number.
+func negate(value string) string {
+	switch {
+	case strings.HasPrefix(value, "-"):
+		return value[1:]
+	case strings.HasPrefix(value, "+"):
+		return "-" + value[1:]
+	}
+
+	return "-" + value
+}
@@ -0,0 +1,58 @@
+package arithmetic
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEvaluate(t *testing.T) {
+	assert := assert.New(t)
+
+	tables := []struct {
+		expression string
+		answer     string
+		err        string
+	}{
+		{"1 + 2 * 3", "7", ""},
+		{"(1 + 2) * 3", "9", ""},
+		{"10 - 4 - 3", "3", ""},
+		{"8 / 4 / 2", "1", ""},
+		{"-2 * -(1.5 + 0.5)", "4", ""},
+		{"+3 - -2", "5", ""},
+		{"1.5e2 / 3", "50", ""},
+		{"0xff + 0b1", "256", ""},
+		{"-(0x7 - 0x1) / 4", "-1", ""},
+		{"1 / 0", "+Inf", ""},
+		{"42", "42", ""},
+		{"", "", "empty expression"},
+		{"1 +", "", "unexpected end of expression"},
+		{"1 2", "", "unexpected 2 at position 3"},
+		{"(1 + 2", "", "missing closing parenthesis for parenthesis at position 1"},
+		{"1 + 2)", "", "unexpected ) at position 6"},
+		{"* 2", "", "unexpected * at position 1"},
+		{"2 ^ 3", "", "unexpected character '^' at position 3"},
+		{"1.. + 2", "", "operator + at position 5: add values: 1.. and 2: value: 1.. not valid number"},
+		{"0x10 / 0", "", "operator / at position 6: integer division by zero"},
+		{"1..", "", "x value: 1.. not valid number"},
+		{strings.Repeat("1+", MaxExpressionOperations+1) + "1", "", "expression exceeds maximum of 100 operations"},
+	}
+
+	for _, table := range tables {
+		result, err := Evaluate(table.expression, Calculate)
+
+		if table.err != "" {
+			assert.EqualError(err, table.err, table.expression)
+			continue
+		}
+
+		if !assert.NoError(err, table.expression) {
+			continue
+		}
+
+		assert.Equal(table.answer, result.Answer, table.expression)
+		assert.Equal(table.expression, result.Expression)
+		assert.False(result.Cached)
+	}
+}
@@ -0,0 +1,179 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+
+package arithmeticpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion7
+
+// ArithmeticClient is the client API for Arithmetic service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type ArithmeticClient interface {
+	// Calculate returns result of single arithmetic operation.
+	Calculate(ctx context.Context, in *CalculateRequest, opts ...grpc.CallOption) (*Result, error)
+	// Batch returns results of multiple arithmetic operations,
+	// failed operations do not fail the whole batch.
+	Batch(ctx context.Context, in *BatchRequest, opts ...grpc.CallOption) (*BatchResponse, error)
+	// Evaluate returns answer of expression with +, -, *, / operators and parentheses,
+	// e.g. (1.5 + 0x10) * -2.
+	Evaluate(ctx context.Context, in *EvaluateRequest, opts ...grpc.CallOption) (*EvaluateResponse, error)
+}
+
+type arithmeticClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewArithmeticClient(cc grpc.ClientConnInterface) ArithmeticClient {
+	return &arithmeticClient{cc}
+}
+
+func (c *arithmeticClient) Calculate(ctx context.Context, in *CalculateRequest, opts ...grpc.CallOption) (*Result, error) {
+	out := new(Result)
+	err := c.cc.Invoke(ctx, "/teltech.arithmetic.v1.Arithmetic/Calculate", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *arithmeticClient) Batch(ctx context.Context, in *BatchRequest, opts ...grpc.CallOption) (*BatchResponse, error) {
+	out := new(BatchResponse)
+	err := c.cc.Invoke(ctx, "/teltech.arithmetic.v1.Arithmetic/Batch", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *arithmeticClient) Evaluate(ctx context.Context, in *EvaluateRequest, opts ...grpc.CallOption) (*EvaluateResponse, error) {
+	out := new(EvaluateResponse)
+	err := c.cc.Invoke(ctx, "/teltech.arithmetic.v1.Arithmetic/Evaluate", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ArithmeticServer is the server API for Arithmetic service.
+// All implementations must embed UnimplementedArithmeticServer
+// for forward compatibility
+type ArithmeticServer interface {
+	// Calculate returns result of single arithmetic operation.
+	Calculate(context.Context, *CalculateRequest) (*Result, error)
+	// Batch returns results of multiple arithmetic operations,
+	// failed operations do not fail the whole batch.
+	Batch(context.Context, *BatchRequest) (*BatchResponse, error)
+	// Evaluate returns answer of expression with +, -, *, / operators and parentheses,
+	// e.g. (1.5 + 0x10) * -2.
+	Evaluate(context.Context, *EvaluateRequest) (*EvaluateResponse, error)
+	mustEmbedUnimplementedArithmeticServer()
+}
+
+// UnimplementedArithmeticServer must be embedded to have forward compatible implementations.
+type UnimplementedArithmeticServer struct {
+}
+
+func (UnimplementedArithmeticServer) Calculate(context.Context, *CalculateRequest) (*Result, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Calculate not implemented")
+}
+func (UnimplementedArithmeticServer) Batch(context.Context, *BatchRequest) (*BatchResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Batch not implemented")
+}
+func (UnimplementedArithmeticServer) Evaluate(context.Context, *EvaluateRequest) (*EvaluateResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Evaluate not implemented")
+}
+func (UnimplementedArithmeticServer) mustEmbedUnimplementedArithmeticServer() {}
+
+// UnsafeArithmeticServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ArithmeticServer will
+// result in compilation errors.
+type UnsafeArithmeticServer interface {
+	mustEmbedUnimplementedArithmeticServer()
+}
+
+func RegisterArithmeticServer(s grpc.ServiceRegistrar, srv ArithmeticServer) {
+	s.RegisterService(&_Arithmetic_serviceDesc, srv)
+}
+
+func _Arithmetic_Calculate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CalculateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ArithmeticServer).Calculate(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/teltech.arithmetic.v1.Arithmetic/Calculate",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ArithmeticServer).Calculate(ctx, req.(*CalculateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Arithmetic_Batch_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ArithmeticServer).Batch(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/teltech.arithmetic.v1.Arithmetic/Batch",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ArithmeticServer).Batch(ctx, req.(*BatchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Arithmetic_Evaluate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(EvaluateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ArithmeticServer).Evaluate(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/teltech.arithmetic.v1.Arithmetic/Evaluate",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ArithmeticServer).Evaluate(ctx, req.(*EvaluateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _Arithmetic_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "teltech.arithmetic.v1.Arithmetic",
+	HandlerType: (*ArithmeticServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Calculate",
+			Handler:    _Arithmetic_Calculate_Handler,
+		},
+		{
+			MethodName: "Batch",
+			Handler:    _Arithmetic_Batch_Handler,
+		},
+		{
+			MethodName: "Evaluate",
+			Handler:    _Arithmetic_Evaluate_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "arithmetic.proto",
+}
@@ -0,0 +1,4 @@
+// Package arithmeticpb contains gRPC arithmetic service generated from arithmetic.proto.
+package arithmeticpb
+
+//go:generate protoc --go_out=paths=source_relative:. --go-grpc_out=paths=source_relative:. arithmetic.proto
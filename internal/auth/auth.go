@@ -0,0 +1,111 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"sync"
+)
+
+// Scopes granted to callers.
+const (
+	ScopeArithmeticRead string = "arithmetic:read"
+	ScopeAdminUsage     string = "admin:usage"
+	ScopeAdminCache     string = "admin:cache"
+	ScopeAdminEvents    string = "admin:events"
+)
+
+// Identity is authenticated caller.
+type Identity struct {
+	Name   string   `json:"name"`
+	Scopes []string `json:"scopes"`
+}
+
+// HasScope checks weather identity is granted scope.
+func (i Identity) HasScope(scope string) bool {
+	for _, s := range i.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+
+	return false
+}
+
+type identityContextKey struct{}
+
+// ContextWithIdentity stores caller identity in ctx.
+func ContextWithIdentity(ctx context.Context, identity Identity) context.Context {
+	return context.WithValue(ctx, identityContextKey{}, identity)
+}
+
+// IdentityFromContext returns caller identity stored in ctx.
+func IdentityFromContext(ctx context.Context) (Identity, bool) {
+	identity, ok := ctx.Value(identityContextKey{}).(Identity)
+	return identity, ok
+}
+
+// Key is API key with caller identity and quotas, zero quota is unlimited.
+type Key struct {
+	Key          string   `mapstructure:"key" json:"-"`
+	Name         string   `mapstructure:"name" json:"name"`
+	Scopes       []string `mapstructure:"scopes" json:"scopes"`
+	DailyQuota   int64    `mapstructure:"daily-quota" json:"daily_quota"`
+	MonthlyQuota int64    `mapstructure:"monthly-quota" json:"monthly_quota"`
+}
+
+// Identity returns caller identity of API key.
+func (k Key) Identity() Identity {
+	return Identity{Name: k.Name, Scopes: k.Scopes}
+}
+
+// KeyStore describes API key lookup operations.
+type KeyStore interface {
+	Lookup(apiKey string) (Key, bool, error)
+	List() ([]Key, error)
+}
+
+// MemoryKeyStore is in memory implementation of API key store.
+type MemoryKeyStore struct {
+	mux  sync.RWMutex
+	keys map[[sha256.Size]byte]Key
+	list []Key
+}
+
+// NewMemoryKeyStore creates a new MemoryKeyStore instance with keys.
+func NewMemoryKeyStore(keys []Key) *MemoryKeyStore {
+	ks := &MemoryKeyStore{}
+	ks.SetKeys(keys)
+
+	return ks
+}
+
+// SetKeys replaces stored keys.
+func (ks *MemoryKeyStore) SetKeys(keys []Key) {
+	hashed := make(map[[sha256.Size]byte]Key, len(keys))
+	for _, key := range keys {
+		hashed[sha256.Sum256([]byte(key.Key))] = key
+	}
+
+	ks.mux.Lock()
+	defer ks.mux.Unlock()
+
+	ks.keys = hashed
+	ks.list = append([]Key(nil), keys...)
+}
+
+// Lookup finds API key, keys are compared by hash so lookup time does not depend on key prefix.
+func (ks *MemoryKeyStore) Lookup(apiKey string) (Key, bool, error) {
+	ks.mux.RLock()
+	defer ks.mux.RUnlock()
+
+	key, ok := ks.keys[sha256.Sum256([]byte(apiKey))]
+	return key, ok, nil
+}
+
+// List returns all stored keys.
+func (ks *MemoryKeyStore) List() ([]Key, error) {
+	ks.mux.RLock()
+	defer ks.mux.RUnlock()
+
+	return append([]Key(nil), ks.list...), nil
+}
@@ -0,0 +1,91 @@
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryKeyStore(t *testing.T) {
+	assert := assert.New(t)
+
+	store := NewMemoryKeyStore([]Key{
+		{Key: "secret", Name: "partner", Scopes: []string{ScopeArithmeticRead}},
+	})
+
+	key, ok, err := store.Lookup("secret")
+	assert.NoError(err, "Error should be nil")
+	assert.True(ok)
+	assert.Equal("partner", key.Name)
+	assert.True(key.Identity().HasScope(ScopeArithmeticRead))
+	assert.False(key.Identity().HasScope(ScopeAdminUsage))
+
+	_, ok, err = store.Lookup("wrong")
+	assert.NoError(err, "Error should be nil")
+	assert.False(ok)
+
+	store.SetKeys(nil)
+	_, ok, _ = store.Lookup("secret")
+	assert.False(ok)
+
+	keys, err := store.List()
+	assert.NoError(err, "Error should be nil")
+	assert.Empty(keys)
+}
+
+func TestIdentityContext(t *testing.T) {
+	assert := assert.New(t)
+
+	_, ok := IdentityFromContext(context.Background())
+	assert.False(ok)
+
+	ctx := ContextWithIdentity(context.Background(), Identity{Name: "partner"})
+	identity, ok := IdentityFromContext(ctx)
+	assert.True(ok)
+	assert.Equal("partner", identity.Name)
+}
+
+func TestMemoryUsageStore(t *testing.T) {
+	assert := assert.New(t)
+
+	store := NewMemoryUsageStore()
+	key := Key{Name: "partner", DailyQuota: 2, MonthlyQuota: 3}
+	day := time.Date(2020, 10, 30, 12, 0, 0, 0, time.UTC)
+
+	tables := []struct {
+		now          time.Time
+		allowed      bool
+		dailyCount   int64
+		monthlyCount int64
+	}{
+		{day, true, 1, 1},
+		{day, true, 2, 2},
+		{day, false, 2, 2},
+		{day.AddDate(0, 0, 1), true, 1, 3},
+		{day.AddDate(0, 0, 1), false, 1, 3},
+		{day.AddDate(0, 0, 2), true, 1, 1},
+	}
+
+	for _, table := range tables {
+		usage, allowed, err := store.Increment(key, table.now)
+
+		assert.NoError(err, "Error should be nil")
+		assert.Equal(table.allowed, allowed, "Values should be the same")
+		assert.Equal(table.dailyCount, usage.DailyCount, "Values should be the same")
+		assert.Equal(table.monthlyCount, usage.MonthlyCount, "Values should be the same")
+	}
+
+	usage, err := store.Get(key, day.AddDate(0, 0, 2))
+	assert.NoError(err, "Error should be nil")
+	assert.Equal(Usage{
+		Name:         "partner",
+		Day:          "2020-11-01",
+		DailyCount:   1,
+		DailyQuota:   2,
+		Month:        "2020-11",
+		MonthlyCount: 1,
+		MonthlyQuota: 3,
+	}, usage)
+}
@@ -0,0 +1,219 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"io/ioutil"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Supported JWT signing algorithms.
+const (
+	AlgHS256 string = "HS256"
+	AlgRS256 string = "RS256"
+)
+
+// defaultLeeway is allowed clock skew when validating exp and nbf claims.
+const defaultLeeway time.Duration = 30 * time.Second
+
+// JWTValidator validates HS256 and RS256 signed bearer tokens.
+type JWTValidator struct {
+	hmacKey  []byte
+	rsaKey   *rsa.PublicKey
+	audience string
+	leeway   time.Duration
+	now      func() time.Time
+}
+
+// NewJWTValidator creates a new JWTValidator instance, tokens are accepted only
+// for algorithms with configured key, empty audience skips aud claim validation.
+func NewJWTValidator(hmacKey []byte, rsaKey *rsa.PublicKey, audience string) *JWTValidator {
+	return &JWTValidator{
+		hmacKey:  hmacKey,
+		rsaKey:   rsaKey,
+		audience: audience,
+		leeway:   defaultLeeway,
+		now:      time.Now,
+	}
+}
+
+type jwtHeader struct {
+	Alg string `json:"alg"`
+	Typ string `json:"typ"`
+}
+
+// audience is aud claim which can be string or array of strings.
+type audience []string
+
+func (a *audience) UnmarshalJSON(b []byte) error {
+	var single string
+	if err := json.Unmarshal(b, &single); err == nil {
+		*a = audience{single}
+		return nil
+	}
+
+	var multiple []string
+	if err := json.Unmarshal(b, &multiple); err != nil {
+		return errors.New("aud claim should be string or array of strings")
+	}
+
+	*a = multiple
+	return nil
+}
+
+type jwtClaims struct {
+	Subject   string   `json:"sub"`
+	Audience  audience `json:"aud"`
+	ExpiresAt *int64   `json:"exp"`
+	NotBefore *int64   `json:"nbf"`
+	Scope     string   `json:"scope"`
+	Scopes    []string `json:"scp"`
+}
+
+// Validate checks token signature, exp, nbf and aud claims and returns caller identity,
+// scopes are read from space separated scope claim and scp array claim.
+func (v *JWTValidator) Validate(token string) (Identity, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return Identity{}, errors.New("token: invalid format")
+	}
+
+	var header jwtHeader
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return Identity{}, errors.Wrap(err, "token header")
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return Identity{}, errors.Wrap(err, "token signature")
+	}
+
+	if err := v.verify(header.Alg, parts[0]+"."+parts[1], signature); err != nil {
+		return Identity{}, err
+	}
+
+	var claims jwtClaims
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return Identity{}, errors.Wrap(err, "token claims")
+	}
+
+	now := v.now()
+
+	if claims.ExpiresAt == nil {
+		return Identity{}, errors.New("token: missing exp claim")
+	}
+
+	if now.After(time.Unix(*claims.ExpiresAt, 0).Add(v.leeway)) {
+		return Identity{}, errors.New("token: expired")
+	}
+
+	if claims.NotBefore != nil && now.Add(v.leeway).Before(time.Unix(*claims.NotBefore, 0)) {
+		return Identity{}, errors.New("token: not valid yet")
+	}
+
+	if v.audience != "" && !claims.Audience.contains(v.audience) {
+		return Identity{}, errors.New("token: invalid audience")
+	}
+
+	scopes := append(strings.Fields(claims.Scope), claims.Scopes...)
+
+	return Identity{Name: claims.Subject, Scopes: scopes}, nil
+}
+
+// verify checks token signature with key of header algorithm.
+func (v *JWTValidator) verify(alg, signingInput string, signature []byte) error {
+	switch {
+	case alg == AlgHS256 && len(v.hmacKey) > 0:
+		mac := hmac.New(sha256.New, v.hmacKey)
+		mac.Write([]byte(signingInput))
+
+		if !hmac.Equal(signature, mac.Sum(nil)) {
+			return errors.New("token: invalid signature")
+		}
+
+		return nil
+
+	case alg == AlgRS256 && v.rsaKey != nil:
+		hash := sha256.Sum256([]byte(signingInput))
+
+		if err := rsa.VerifyPKCS1v15(v.rsaKey, crypto.SHA256, hash[:], signature); err != nil {
+			return errors.New("token: invalid signature")
+		}
+
+		return nil
+	}
+
+	return errors.Errorf("token: unsupported algorithm %s", alg)
+}
+
+func (a audience) contains(aud string) bool {
+	for _, value := range a {
+		if value == aud {
+			return true
+		}
+	}
+
+	return false
+}
+
+func decodeSegment(segment string, v interface{}) error {
+	b, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(b, v)
+}
+
+// LoadHMACKey reads HS256 secret from file, surrounding whitespace is trimmed.
+func LoadHMACKey(path string) ([]byte, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "reading HMAC key file")
+	}
+
+	key := []byte(strings.TrimSpace(string(b)))
+	if len(key) == 0 {
+		return nil, errors.Errorf("HMAC key file: %s is empty", path)
+	}
+
+	return key, nil
+}
+
+// LoadRSAPublicKey reads RS256 public key from PEM file with PKIX or PKCS1 public key.
+func LoadRSAPublicKey(path string) (*rsa.PublicKey, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "reading RSA public key file")
+	}
+
+	block, _ := pem.Decode(b)
+	if block == nil {
+		return nil, errors.Errorf("RSA public key file: %s is not PEM encoded", path)
+	}
+
+	if key, err := x509.ParsePKCS1PublicKey(block.Bytes); err == nil {
+		return key, nil
+	}
+
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, errors.Wrap(err, "parsing RSA public key")
+	}
+
+	rsaKey, ok := key.(*rsa.PublicKey)
+	if !ok {
+		return nil, errors.Errorf("RSA public key file: %s does not contain RSA key", path)
+	}
+
+	return rsaKey, nil
+}
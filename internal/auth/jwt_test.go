@@ -0,0 +1,130 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func signToken(t *testing.T, alg string, claims map[string]interface{}, hmacKey []byte, rsaKey *rsa.PrivateKey) string {
+	header, _ := json.Marshal(map[string]string{"alg": alg, "typ": "JWT"})
+	payload, _ := json.Marshal(claims)
+
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+
+	var signature []byte
+	switch alg {
+	case AlgHS256:
+		mac := hmac.New(sha256.New, hmacKey)
+		mac.Write([]byte(signingInput))
+		signature = mac.Sum(nil)
+	case AlgRS256:
+		hash := sha256.Sum256([]byte(signingInput))
+
+		var err error
+		signature, err = rsa.SignPKCS1v15(rand.Reader, rsaKey, crypto.SHA256, hash[:])
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func TestJWTValidator(t *testing.T) {
+	assert := assert.New(t)
+
+	dir, err := ioutil.TempDir("", "jwt")
+	assert.NoError(err, "Error should be nil")
+	defer os.RemoveAll(dir)
+
+	// Keys are loaded from files
+	hmacKeyFile := filepath.Join(dir, "hmac.key")
+	assert.NoError(ioutil.WriteFile(hmacKeyFile, []byte("secret\n"), 0600))
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(err, "Error should be nil")
+
+	publicKey, err := x509.MarshalPKIXPublicKey(&rsaKey.PublicKey)
+	assert.NoError(err, "Error should be nil")
+
+	rsaKeyFile := filepath.Join(dir, "rsa.pem")
+	assert.NoError(ioutil.WriteFile(rsaKeyFile, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicKey}), 0600))
+
+	hmacKey, err := LoadHMACKey(hmacKeyFile)
+	assert.NoError(err, "Error should be nil")
+	assert.Equal([]byte("secret"), hmacKey)
+
+	rsaPublicKey, err := LoadRSAPublicKey(rsaKeyFile)
+	assert.NoError(err, "Error should be nil")
+
+	validator := NewJWTValidator(hmacKey, rsaPublicKey, "arithmetic")
+	now := time.Now()
+
+	claims := func(overrides map[string]interface{}) map[string]interface{} {
+		c := map[string]interface{}{
+			"sub":   "gateway",
+			"aud":   "arithmetic",
+			"exp":   now.Add(time.Hour).Unix(),
+			"nbf":   now.Add(-time.Hour).Unix(),
+			"scope": "arithmetic:read admin:cache",
+		}
+
+		for k, v := range overrides {
+			if v == nil {
+				delete(c, k)
+				continue
+			}
+
+			c[k] = v
+		}
+
+		return c
+	}
+
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(err, "Error should be nil")
+
+	tables := []struct {
+		name    string
+		token   string
+		errFlag bool
+	}{
+		{"hs256", signToken(t, AlgHS256, claims(nil), hmacKey, nil), false},
+		{"rs256", signToken(t, AlgRS256, claims(nil), nil, rsaKey), false},
+		{"audience array", signToken(t, AlgHS256, claims(map[string]interface{}{"aud": []string{"other", "arithmetic"}}), hmacKey, nil), false},
+		{"wrong hmac key", signToken(t, AlgHS256, claims(nil), []byte("other"), nil), true},
+		{"wrong rsa key", signToken(t, AlgRS256, claims(nil), nil, otherKey), true},
+		{"unsupported algorithm", signToken(t, "none", claims(nil), nil, nil), true},
+		{"expired", signToken(t, AlgHS256, claims(map[string]interface{}{"exp": now.Add(-time.Hour).Unix()}), hmacKey, nil), true},
+		{"missing exp", signToken(t, AlgHS256, claims(map[string]interface{}{"exp": nil}), hmacKey, nil), true},
+		{"not valid yet", signToken(t, AlgHS256, claims(map[string]interface{}{"nbf": now.Add(time.Hour).Unix()}), hmacKey, nil), true},
+		{"wrong audience", signToken(t, AlgHS256, claims(map[string]interface{}{"aud": "other"}), hmacKey, nil), true},
+		{"invalid format", "token", true},
+	}
+
+	for _, table := range tables {
+		identity, err := validator.Validate(table.token)
+
+		if table.errFlag {
+			assert.Error(err, "Should be error: %s", table.name)
+			continue
+		}
+
+		assert.NoError(err, "Error should be nil: %s", table.name)
+		assert.Equal(Identity{Name: "gateway", Scopes: []string{ScopeArithmeticRead, ScopeAdminCache}}, identity)
+	}
+}
@@ -0,0 +1,97 @@
+package auth
+
+import (
+	"sync"
+	"time"
+)
+
+// Quota periods formats.
+const (
+	dayFormat   string = "2006-01-02"
+	monthFormat string = "2006-01"
+)
+
+// Usage contains caller request counts in current day and month.
+type Usage struct {
+	Name         string `json:"name"`
+	Day          string `json:"day"`
+	DailyCount   int64  `json:"daily_count"`
+	DailyQuota   int64  `json:"daily_quota"`
+	Month        string `json:"month"`
+	MonthlyCount int64  `json:"monthly_count"`
+	MonthlyQuota int64  `json:"monthly_quota"`
+}
+
+// UsageStore describes quota usage operations,
+// implementations can share usage across instances.
+type UsageStore interface {
+	// Increment counts request of key if it is within quotas.
+	Increment(key Key, now time.Time) (Usage, bool, error)
+	// Get returns current usage of key.
+	Get(key Key, now time.Time) (Usage, error)
+}
+
+// MemoryUsageStore is in memory implementation of quota usage store.
+type MemoryUsageStore struct {
+	mux   sync.Mutex
+	usage map[string]*Usage
+}
+
+// NewMemoryUsageStore creates a new MemoryUsageStore instance.
+func NewMemoryUsageStore() *MemoryUsageStore {
+	return &MemoryUsageStore{
+		usage: make(map[string]*Usage),
+	}
+}
+
+// Increment counts request of key if daily and monthly quotas are not exceeded.
+func (us *MemoryUsageStore) Increment(key Key, now time.Time) (Usage, bool, error) {
+	us.mux.Lock()
+	defer us.mux.Unlock()
+
+	usage := us.current(key, now)
+
+	if (key.DailyQuota > 0 && usage.DailyCount >= key.DailyQuota) ||
+		(key.MonthlyQuota > 0 && usage.MonthlyCount >= key.MonthlyQuota) {
+		return *usage, false, nil
+	}
+
+	usage.DailyCount++
+	usage.MonthlyCount++
+
+	return *usage, true, nil
+}
+
+// Get returns usage of key in current day and month.
+func (us *MemoryUsageStore) Get(key Key, now time.Time) (Usage, error) {
+	us.mux.Lock()
+	defer us.mux.Unlock()
+
+	return *us.current(key, now), nil
+}
+
+// current returns usage of key, counters are reset when day or month changes.
+func (us *MemoryUsageStore) current(key Key, now time.Time) *Usage {
+	day, month := now.UTC().Format(dayFormat), now.UTC().Format(monthFormat)
+
+	usage, ok := us.usage[key.Name]
+	if !ok {
+		usage = &Usage{Name: key.Name, Day: day, Month: month}
+		us.usage[key.Name] = usage
+	}
+
+	if usage.Day != day {
+		usage.Day = day
+		usage.DailyCount = 0
+	}
+
+	if usage.Month != month {
+		usage.Month = month
+		usage.MonthlyCount = 0
+	}
+
+	usage.DailyQuota = key.DailyQuota
+	usage.MonthlyQuota = key.MonthlyQuota
+
+	return usage
+}
@@ -17,6 +17,18 @@ type Cache struct {
 	ll    *list.List
 	cache map[interface{}]*list.Element
 	mux   sync.Mutex
+
+	hits      uint64
+	misses    uint64
+	evictions uint64
+}
+
+// Stats contains cache usage counters.
+type Stats struct {
+	Hits      uint64
+	Misses    uint64
+	Evictions uint64
+	Len       int
 }
 
 // Key can be any comparable type.
@@ -81,32 +93,95 @@ func (c *Cache) Get(key Key) (value interface{}, ok bool) {
 	if element, hit := c.cache[key]; hit {
 		element.Value.(*entry).ttl = time.Now().Add(c.recordTTL)
 		c.ll.MoveToFront(element)
+		c.hits++
 		return element.Value.(*entry).value, true
 	}
 
+	c.misses++
 	return
 }
 
+// Purge removes all records from the cache.
+func (c *Cache) Purge() {
+	if c.cache == nil {
+		return
+	}
+
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	// Map is cleared in place, so nil checks of c.cache outside of lock don't race with Purge.
+	for key := range c.cache {
+		delete(c.cache, key)
+	}
+
+	c.ll.Init()
+}
+
+// Stats returns cache usage counters.
+func (c *Cache) Stats() Stats {
+	if c.cache == nil {
+		return Stats{}
+	}
+
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	return Stats{
+		Hits:      c.hits,
+		Misses:    c.misses,
+		Evictions: c.evictions,
+		Len:       c.ll.Len(),
+	}
+}
+
+// SetLimits updates maximum cache size and record TTL,
+// zero values leave the current limit unchanged.
+func (c *Cache) SetLimits(cacheSize int, recordTTL time.Duration) {
+	if c.cache == nil {
+		return
+	}
+
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	if cacheSize > 0 {
+		c.cacheSize = cacheSize
+	}
+
+	if recordTTL > 0 {
+		c.recordTTL = recordTTL
+	}
+
+	c.checkSizeAndExpiredRecords()
+}
+
 // checkSizeAndExpiredRecords checks cache size and remove elements from the back,
 // removes expired records from the cache.
 func (c *Cache) checkSizeAndExpiredRecords() {
 	element := c.ll.Back()
 	for element != nil {
+		prev := element.Prev()
+
 		entry := element.Value.(*entry)
 		if time.Now().After(entry.ttl) {
 			c.ll.Remove(element)
 			delete(c.cache, entry.key)
+			c.evictions++
 		}
 
-		element = element.Prev()
+		element = prev
 	}
 
 	element = c.ll.Back()
 	for element != nil && c.ll.Len() > c.cacheSize {
+		prev := element.Prev()
+
 		entry := element.Value.(*entry)
 		c.ll.Remove(element)
 		delete(c.cache, entry.key)
+		c.evictions++
 
-		element = element.Prev()
+		element = prev
 	}
 }
@@ -1,6 +1,7 @@
 package cache
 
 import (
+	"net/url"
 	"time"
 )
 
@@ -10,6 +11,31 @@ type Store interface {
 	GetRecord(key string) (interface{}, bool)
 }
 
+// StatsProvider describes cache stores which expose usage statistics.
+type StatsProvider interface {
+	Stats() Stats
+}
+
+// Pinger describes cache stores which can check their availability.
+type Pinger interface {
+	Ping() error
+}
+
+// Purger describes cache stores which can remove all records.
+type Purger interface {
+	Purge()
+}
+
+// OperationKey returns key of arithmetic operation result, it matches request URL
+// of HTTP endpoint with x and y query params so results are shared between APIs.
+func OperationKey(action, x, y string) string {
+	params := url.Values{}
+	params.Add("x", x)
+	params.Add("y", y)
+
+	return "/" + action + "?" + params.Encode()
+}
+
 // InMemoryStore is in memory implementation of cache store.
 type InMemoryStore struct {
 	cache *Cache
@@ -25,6 +51,26 @@ func (i *InMemoryStore) GetRecord(key string) (interface{}, bool) {
 	return i.cache.Get(key)
 }
 
+// SetLimits updates cache size and record TTL of the running cache.
+func (i *InMemoryStore) SetLimits(cacheSize int, recordTTL time.Duration) {
+	i.cache.SetLimits(cacheSize, recordTTL)
+}
+
+// Stats returns cache usage counters.
+func (i *InMemoryStore) Stats() Stats {
+	return i.cache.Stats()
+}
+
+// Ping checks cache availability, in memory cache is always available.
+func (i *InMemoryStore) Ping() error {
+	return nil
+}
+
+// Purge removes all records from cache.
+func (i *InMemoryStore) Purge() {
+	i.cache.Purge()
+}
+
 // NewStore returns new in memory cache store instance.
 func NewStore(cacheSize int, recordTTL time.Duration) *InMemoryStore {
 	return &InMemoryStore{
@@ -1,6 +1,8 @@
 package cache
 
 import (
+	"strconv"
+	"sync"
 	"testing"
 	"time"
 
@@ -81,3 +83,89 @@ func TestCacheSize(t *testing.T) {
 	_, ok = store.GetRecord("1")
 	assert.False(ok)
 }
+
+func TestSetLimits(t *testing.T) {
+	assert := assert.New(t)
+
+	store := NewStore(3, 1*time.Second)
+	store.StoreRecord("1", 1)
+	store.StoreRecord("2", 2)
+	store.StoreRecord("3", 3)
+
+	store.SetLimits(1, 2*time.Second)
+	assert.Equal(1, store.cache.cacheSize)
+	assert.Equal(2*time.Second, store.cache.recordTTL)
+	assert.Equal(1, store.cache.ll.Len())
+
+	value, ok := store.GetRecord("3")
+	assert.Equal(3, value)
+	assert.True(ok)
+
+	store.SetLimits(0, 0)
+	assert.Equal(1, store.cache.cacheSize)
+	assert.Equal(2*time.Second, store.cache.recordTTL)
+}
+
+func TestStats(t *testing.T) {
+	assert := assert.New(t)
+
+	store := NewStore(1, 1*time.Second)
+	store.StoreRecord("1", 1)
+
+	_, ok := store.GetRecord("1")
+	assert.True(ok)
+
+	_, ok = store.GetRecord("2")
+	assert.False(ok)
+
+	store.StoreRecord("2", 2)
+	store.StoreRecord("3", 3)
+
+	assert.Equal(Stats{Hits: 1, Misses: 1, Evictions: 1, Len: 2}, store.Stats())
+}
+
+func TestPurge(t *testing.T) {
+	assert := assert.New(t)
+
+	store := NewStore(10, 1*time.Second)
+	store.StoreRecord("1", 1)
+	store.StoreRecord("2", 2)
+
+	store.Purge()
+	assert.Equal(0, store.cache.ll.Len())
+
+	_, ok := store.GetRecord("1")
+	assert.False(ok)
+}
+
+func TestConcurrentPurge(t *testing.T) {
+	assert := assert.New(t)
+
+	store := NewStore(100, 1*time.Minute)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				key := strconv.Itoa(i*100 + j)
+				store.StoreRecord(key, j)
+				store.GetRecord(key)
+				store.Stats()
+			}
+		}(i)
+
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				store.Purge()
+			}
+		}()
+	}
+
+	wg.Wait()
+	store.Purge()
+	assert.Equal(0, store.Stats().Len)
+}
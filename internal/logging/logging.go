@@ -0,0 +1,162 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Level is log message severity.
+type Level int32
+
+// Log levels.
+const (
+	DebugLevel Level = iota
+	InfoLevel
+	WarnLevel
+	ErrorLevel
+)
+
+var levelNames = map[Level]string{
+	DebugLevel: "debug",
+	InfoLevel:  "info",
+	WarnLevel:  "warn",
+	ErrorLevel: "error",
+}
+
+// String returns level name.
+func (l Level) String() string {
+	if name, ok := levelNames[l]; ok {
+		return name
+	}
+
+	return fmt.Sprintf("level(%d)", int32(l))
+}
+
+// ParseLevel converts level name to Level.
+func ParseLevel(name string) (Level, error) {
+	for level, levelName := range levelNames {
+		if strings.EqualFold(name, levelName) {
+			return level, nil
+		}
+	}
+
+	return InfoLevel, fmt.Errorf("unknown log level: %s", name)
+}
+
+// Fields are key value pairs attached to log line.
+type Fields map[string]interface{}
+
+// Logger writes log lines as JSON objects with level, timestamp and attached fields.
+type Logger struct {
+	out    io.Writer
+	mux    *sync.Mutex
+	level  *int32
+	fields Fields
+}
+
+// New creates a new Logger instance writing to out.
+func New(out io.Writer, level Level) *Logger {
+	l := int32(level)
+
+	return &Logger{
+		out:   out,
+		mux:   &sync.Mutex{},
+		level: &l,
+	}
+}
+
+// SetLevel changes minimum level of written log lines,
+// the change applies to all loggers derived with With.
+func (l *Logger) SetLevel(level Level) {
+	atomic.StoreInt32(l.level, int32(level))
+}
+
+// Level returns minimum level of written log lines.
+func (l *Logger) Level() Level {
+	return Level(atomic.LoadInt32(l.level))
+}
+
+// With returns logger which adds fields to every log line.
+func (l *Logger) With(fields Fields) *Logger {
+	merged := make(Fields, len(l.fields)+len(fields))
+	for k, v := range l.fields {
+		merged[k] = v
+	}
+
+	for k, v := range fields {
+		merged[k] = v
+	}
+
+	return &Logger{
+		out:    l.out,
+		mux:    l.mux,
+		level:  l.level,
+		fields: merged,
+	}
+}
+
+// Debugf writes formatted message with debug level.
+func (l *Logger) Debugf(format string, args ...interface{}) {
+	l.Log(DebugLevel, fmt.Sprintf(format, args...), nil)
+}
+
+// Infof writes formatted message with info level.
+func (l *Logger) Infof(format string, args ...interface{}) {
+	l.Log(InfoLevel, fmt.Sprintf(format, args...), nil)
+}
+
+// Warnf writes formatted message with warn level.
+func (l *Logger) Warnf(format string, args ...interface{}) {
+	l.Log(WarnLevel, fmt.Sprintf(format, args...), nil)
+}
+
+// Errorf writes formatted message with error level.
+func (l *Logger) Errorf(format string, args ...interface{}) {
+	l.Log(ErrorLevel, fmt.Sprintf(format, args...), nil)
+}
+
+// Log writes message with given level and additional fields.
+func (l *Logger) Log(level Level, msg string, fields Fields) {
+	if level < l.Level() {
+		return
+	}
+
+	line := make(Fields, len(l.fields)+len(fields)+3)
+	for k, v := range l.fields {
+		line[k] = v
+	}
+
+	for k, v := range fields {
+		line[k] = v
+	}
+
+	line["time"] = time.Now().UTC().Format(time.RFC3339Nano)
+	line["level"] = level.String()
+	line["msg"] = msg
+
+	for k, v := range line {
+		if err, ok := v.(error); ok {
+			line[k] = err.Error()
+		}
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+
+	if err := enc.Encode(line); err != nil {
+		buf.Reset()
+		fmt.Fprintf(&buf, "{\"level\":\"error\",\"msg\":%q}\n", "could not marshal log line: "+err.Error())
+	}
+
+	l.mux.Lock()
+	defer l.mux.Unlock()
+
+	_, _ = l.out.Write(buf.Bytes())
+}
@@ -0,0 +1,69 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseLevel(t *testing.T) {
+	assert := assert.New(t)
+
+	tables := []struct {
+		name    string
+		level   Level
+		errFlag bool
+	}{
+		{"debug", DebugLevel, false},
+		{"INFO", InfoLevel, false},
+		{"warn", WarnLevel, false},
+		{"error", ErrorLevel, false},
+		{"verbose", InfoLevel, true},
+	}
+
+	for _, table := range tables {
+		level, err := ParseLevel(table.name)
+
+		assert.Equal(table.level, level, "Values should be the same")
+
+		if table.errFlag {
+			assert.Error(err, "Should be error")
+		} else {
+			assert.NoError(err, "Error should be nil")
+		}
+	}
+}
+
+func TestLog(t *testing.T) {
+	assert := assert.New(t)
+
+	var buf bytes.Buffer
+	logger := New(&buf, InfoLevel)
+	requestLogger := logger.With(Fields{"request_id": "abc"})
+
+	requestLogger.Debugf("not written")
+	requestLogger.Log(InfoLevel, "written", Fields{"error": errors.New("failed")})
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Len(lines, 1)
+
+	var line map[string]interface{}
+	assert.NoError(json.Unmarshal([]byte(lines[0]), &line))
+	assert.Equal("info", line["level"])
+	assert.Equal("written", line["msg"])
+	assert.Equal("abc", line["request_id"])
+	assert.Equal("failed", line["error"])
+	assert.NotEmpty(line["time"])
+
+	// Level change applies to derived loggers
+	buf.Reset()
+	logger.SetLevel(DebugLevel)
+	requestLogger.Debugf("written %d", 1)
+
+	assert.Contains(buf.String(), `"msg":"written 1"`)
+	assert.Contains(buf.String(), `"level":"debug"`)
+}
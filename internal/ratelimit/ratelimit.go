@@ -0,0 +1,113 @@
+package ratelimit
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// Limit describes token bucket which refills with Rate tokens per second up to Burst tokens.
+type Limit struct {
+	Rate  float64 `mapstructure:"rate"`
+	Burst int     `mapstructure:"burst"`
+}
+
+// IsZero checks weather limit is disabled.
+func (l Limit) IsZero() bool {
+	return l.Rate <= 0 || l.Burst <= 0
+}
+
+// Result contains outcome of taking a token from a bucket.
+type Result struct {
+	Allowed bool
+	Limit   int
+	// Remaining is number of tokens left in the bucket.
+	Remaining int
+	// RetryAfter is duration until next token is available when request is not allowed.
+	RetryAfter time.Duration
+	// Reset is duration until bucket is full again.
+	Reset time.Duration
+}
+
+// Backend stores token buckets, implementations can share state across instances.
+type Backend interface {
+	Take(key string, limit Limit, now time.Time) (Result, error)
+}
+
+type bucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+// MemoryBackend is in memory implementation of rate limiter backend.
+type MemoryBackend struct {
+	// idleTTL is duration of bucket inactivity past which is removed.
+	idleTTL time.Duration
+
+	buckets   map[string]*bucket
+	lastSweep time.Time
+	mux       sync.Mutex
+}
+
+// NewMemoryBackend creates a new MemoryBackend instance,
+// zero idleTTL value is 10 min.
+func NewMemoryBackend(idleTTL time.Duration) *MemoryBackend {
+	if idleTTL == 0 {
+		idleTTL = 10 * time.Minute
+	}
+
+	return &MemoryBackend{
+		idleTTL: idleTTL,
+		buckets: make(map[string]*bucket),
+	}
+}
+
+// Take takes one token from the bucket stored under key.
+func (mb *MemoryBackend) Take(key string, limit Limit, now time.Time) (Result, error) {
+	mb.mux.Lock()
+	defer mb.mux.Unlock()
+
+	mb.sweep(now)
+
+	b, ok := mb.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(limit.Burst), updated: now}
+		mb.buckets[key] = b
+	}
+
+	b.tokens = math.Min(float64(limit.Burst), b.tokens+now.Sub(b.updated).Seconds()*limit.Rate)
+	b.updated = now
+
+	result := Result{Limit: limit.Burst}
+
+	if b.tokens >= 1 {
+		b.tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = secondsToDuration((1 - b.tokens) / limit.Rate)
+	}
+
+	result.Remaining = int(math.Floor(b.tokens))
+	result.Reset = secondsToDuration((float64(limit.Burst) - b.tokens) / limit.Rate)
+
+	return result, nil
+}
+
+// sweep removes buckets which were not used for idleTTL duration.
+func (mb *MemoryBackend) sweep(now time.Time) {
+	if now.Sub(mb.lastSweep) < mb.idleTTL {
+		return
+	}
+
+	for key, b := range mb.buckets {
+		if now.Sub(b.updated) > mb.idleTTL {
+			delete(mb.buckets, key)
+		}
+	}
+
+	mb.lastSweep = now
+}
+
+func secondsToDuration(seconds float64) time.Duration {
+	return time.Duration(math.Ceil(seconds * float64(time.Second)))
+}
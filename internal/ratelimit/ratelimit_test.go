@@ -0,0 +1,53 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTake(t *testing.T) {
+	assert := assert.New(t)
+
+	backend := NewMemoryBackend(1 * time.Minute)
+	limit := Limit{Rate: 1, Burst: 2}
+	now := time.Now()
+
+	result, err := backend.Take("client", limit, now)
+	assert.NoError(err, "Error should be nil")
+	assert.Equal(Result{Allowed: true, Limit: 2, Remaining: 1, Reset: 1 * time.Second}, result)
+
+	result, err = backend.Take("client", limit, now)
+	assert.NoError(err, "Error should be nil")
+	assert.Equal(Result{Allowed: true, Limit: 2, Remaining: 0, Reset: 2 * time.Second}, result)
+
+	result, err = backend.Take("client", limit, now)
+	assert.NoError(err, "Error should be nil")
+	assert.Equal(Result{Allowed: false, Limit: 2, Remaining: 0, RetryAfter: 1 * time.Second, Reset: 2 * time.Second}, result)
+
+	// Other keys have their own bucket
+	result, err = backend.Take("other", limit, now)
+	assert.NoError(err, "Error should be nil")
+	assert.True(result.Allowed)
+
+	// Bucket refills with time
+	result, err = backend.Take("client", limit, now.Add(1500*time.Millisecond))
+	assert.NoError(err, "Error should be nil")
+	assert.True(result.Allowed)
+	assert.Equal(0, result.Remaining)
+}
+
+func TestSweep(t *testing.T) {
+	assert := assert.New(t)
+
+	backend := NewMemoryBackend(1 * time.Minute)
+	limit := Limit{Rate: 1, Burst: 1}
+	now := time.Now()
+
+	_, _ = backend.Take("client", limit, now)
+	_, _ = backend.Take("other", limit, now.Add(2*time.Minute))
+
+	assert.Len(backend.buckets, 1)
+	assert.Contains(backend.buckets, "other")
+}
@@ -0,0 +1,145 @@
+package tlsutil
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Client certificate verification modes.
+const (
+	ClientAuthNone    string = "none"
+	ClientAuthRequest string = "request"
+	ClientAuthRequire string = "require"
+)
+
+// defaultCheckInterval is minimum duration between certificate file checks.
+const defaultCheckInterval time.Duration = 1 * time.Second
+
+// CertReloader serves certificate from cert and key files and reloads it when files change.
+type CertReloader struct {
+	certFile string
+	keyFile  string
+	onError  func(err error)
+
+	mux       sync.Mutex
+	cert      *tls.Certificate
+	modTime   time.Time
+	lastCheck time.Time
+}
+
+// NewCertReloader creates a new CertReloader instance and loads certificate,
+// reload errors are passed to onError if set and previous certificate is kept.
+func NewCertReloader(certFile, keyFile string, onError func(err error)) (*CertReloader, error) {
+	cr := &CertReloader{
+		certFile: certFile,
+		keyFile:  keyFile,
+		onError:  onError,
+	}
+
+	modTime, err := cr.filesModTime()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cr.load(modTime); err != nil {
+		return nil, err
+	}
+
+	return cr, nil
+}
+
+// GetCertificate returns current certificate, used as tls.Config GetCertificate.
+func (cr *CertReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	cr.mux.Lock()
+	defer cr.mux.Unlock()
+
+	if time.Since(cr.lastCheck) >= defaultCheckInterval {
+		cr.lastCheck = time.Now()
+
+		modTime, err := cr.filesModTime()
+		if err == nil && !modTime.Equal(cr.modTime) {
+			err = cr.load(modTime)
+		}
+
+		if err != nil && cr.onError != nil {
+			cr.onError(err)
+		}
+	}
+
+	return cr.cert, nil
+}
+
+// load reads certificate files, caller should hold the lock after initialization.
+func (cr *CertReloader) load(modTime time.Time) error {
+	cert, err := tls.LoadX509KeyPair(cr.certFile, cr.keyFile)
+	if err != nil {
+		return errors.Wrap(err, "loading TLS certificate")
+	}
+
+	cr.cert = &cert
+	cr.modTime = modTime
+
+	return nil
+}
+
+// filesModTime returns latest modification time of cert and key files.
+func (cr *CertReloader) filesModTime() (time.Time, error) {
+	var latest time.Time
+
+	for _, file := range []string{cr.certFile, cr.keyFile} {
+		info, err := os.Stat(file)
+		if err != nil {
+			return latest, errors.Wrap(err, "checking TLS certificate file")
+		}
+
+		if info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+	}
+
+	return latest, nil
+}
+
+// NewServerConfig creates server TLS config serving certificate from reloader,
+// client certificates are verified against CA file when clientAuth is request or require.
+func NewServerConfig(reloader *CertReloader, clientCAFile, clientAuth string) (*tls.Config, error) {
+	config := &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: reloader.GetCertificate,
+	}
+
+	switch clientAuth {
+	case "", ClientAuthNone:
+		return config, nil
+	case ClientAuthRequest:
+		config.ClientAuth = tls.VerifyClientCertIfGiven
+	case ClientAuthRequire:
+		config.ClientAuth = tls.RequireAndVerifyClientCert
+	default:
+		return nil, errors.Errorf("unknown client auth mode: %s", clientAuth)
+	}
+
+	if clientCAFile == "" {
+		return nil, errors.New("client CA file is required for client certificate verification")
+	}
+
+	b, err := ioutil.ReadFile(clientCAFile)
+	if err != nil {
+		return nil, errors.Wrap(err, "reading client CA file")
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(b) {
+		return nil, errors.Errorf("client CA file: %s contains no PEM certificates", clientCAFile)
+	}
+
+	config.ClientCAs = pool
+
+	return config, nil
+}
@@ -0,0 +1,187 @@
+package tlsutil
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"log"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// writeCert creates certificate signed by parent, self signed when parent is nil,
+// and writes PEM encoded certificate and key to dir.
+func writeCert(t *testing.T, dir, name string, isCA bool, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) (*x509.Certificate, *ecdsa.PrivateKey) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: name},
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  isCA,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+
+	if parent == nil {
+		parent, parentKey = template, key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+
+	if err := ioutil.WriteFile(filepath.Join(dir, name+".crt"), certPEM, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ioutil.WriteFile(filepath.Join(dir, name+".key"), keyPEM, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return cert, key
+}
+
+func TestCertReloader(t *testing.T) {
+	assert := assert.New(t)
+
+	dir, err := ioutil.TempDir("", "tlsutil")
+	assert.NoError(err, "Error should be nil")
+	defer os.RemoveAll(dir)
+
+	first, _ := writeCert(t, dir, "server", false, nil, nil)
+	certFile, keyFile := filepath.Join(dir, "server.crt"), filepath.Join(dir, "server.key")
+
+	reloader, err := NewCertReloader(certFile, keyFile, nil)
+	assert.NoError(err, "Error should be nil")
+
+	cert, err := reloader.GetCertificate(nil)
+	assert.NoError(err, "Error should be nil")
+	assert.Equal(first.Raw, cert.Certificate[0])
+
+	// Test that changed certificate is loaded
+	second, _ := writeCert(t, dir, "server", false, nil, nil)
+	future := time.Now().Add(time.Minute)
+	assert.NoError(os.Chtimes(certFile, future, future))
+	reloader.lastCheck = time.Time{}
+
+	cert, err = reloader.GetCertificate(nil)
+	assert.NoError(err, "Error should be nil")
+	assert.Equal(second.Raw, cert.Certificate[0])
+
+	// Test that invalid certificate keeps previous one
+	var reloadErr error
+	reloader.onError = func(err error) { reloadErr = err }
+
+	assert.NoError(ioutil.WriteFile(certFile, []byte("invalid"), 0600))
+	future = future.Add(time.Minute)
+	assert.NoError(os.Chtimes(certFile, future, future))
+	reloader.lastCheck = time.Time{}
+
+	cert, err = reloader.GetCertificate(nil)
+	assert.NoError(err, "Error should be nil")
+	assert.Equal(second.Raw, cert.Certificate[0])
+	assert.Error(reloadErr, "Should be error")
+
+	_, err = NewCertReloader(filepath.Join(dir, "missing.crt"), keyFile, nil)
+	assert.Error(err, "Should be error")
+}
+
+func TestNewServerConfig(t *testing.T) {
+	assert := assert.New(t)
+
+	dir, err := ioutil.TempDir("", "tlsutil")
+	assert.NoError(err, "Error should be nil")
+	defer os.RemoveAll(dir)
+
+	ca, caKey := writeCert(t, dir, "ca", true, nil, nil)
+	writeCert(t, dir, "server", false, ca, caKey)
+	writeCert(t, dir, "client", false, ca, caKey)
+
+	reloader, err := NewCertReloader(filepath.Join(dir, "server.crt"), filepath.Join(dir, "server.key"), nil)
+	assert.NoError(err, "Error should be nil")
+
+	_, err = NewServerConfig(reloader, "", ClientAuthRequire)
+	assert.Error(err, "Should be error")
+
+	_, err = NewServerConfig(reloader, "", "optional")
+	assert.Error(err, "Should be error")
+
+	config, err := NewServerConfig(reloader, filepath.Join(dir, "ca.crt"), ClientAuthRequire)
+	assert.NoError(err, "Error should be nil")
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(err, "Error should be nil")
+
+	server := &http.Server{
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}),
+		TLSConfig: config,
+		ErrorLog:  log.New(ioutil.Discard, "", 0),
+	}
+
+	go func() {
+		_ = server.ServeTLS(listener, "", "")
+	}()
+	defer server.Close()
+
+	serverURL := "https://" + listener.Addr().String()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca)
+
+	clientCert, err := tls.LoadX509KeyPair(filepath.Join(dir, "client.crt"), filepath.Join(dir, "client.key"))
+	assert.NoError(err, "Error should be nil")
+
+	// Test that client without certificate is rejected
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: roots}}}
+	_, err = client.Get(serverURL)
+	assert.Error(err, "Should be error")
+
+	// Test that client with certificate signed by CA is accepted
+	client = &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{
+		RootCAs:      roots,
+		Certificates: []tls.Certificate{clientCert},
+	}}}
+
+	resp, err := client.Get(serverURL)
+	assert.NoError(err, "Error should be nil")
+
+	if resp != nil {
+		resp.Body.Close()
+		assert.Equal(http.StatusOK, resp.StatusCode)
+	}
+}
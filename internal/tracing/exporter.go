@@ -0,0 +1,322 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// WriterExporter writes finished spans as JSON lines, used with stdout or file for local testing.
+type WriterExporter struct {
+	out io.Writer
+	// file is set when exporter opened output itself and closes it on shutdown.
+	file *os.File
+	mux  sync.Mutex
+}
+
+// NewWriterExporter creates a new WriterExporter instance writing to out, out is not closed on shutdown.
+func NewWriterExporter(out io.Writer) *WriterExporter {
+	return &WriterExporter{out: out}
+}
+
+// NewFileExporter creates a new WriterExporter instance appending to file at path,
+// file is closed on shutdown.
+func NewFileExporter(path string) (*WriterExporter, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, errors.Wrap(err, "opening trace file")
+	}
+
+	return &WriterExporter{out: file, file: file}, nil
+}
+
+type writerSpan struct {
+	Name         string                 `json:"name"`
+	TraceID      string                 `json:"trace_id"`
+	SpanID       string                 `json:"span_id"`
+	ParentSpanID string                 `json:"parent_span_id,omitempty"`
+	Start        time.Time              `json:"start"`
+	End          time.Time              `json:"end"`
+	DurationMs   float64                `json:"duration_ms"`
+	Attributes   map[string]interface{} `json:"attributes,omitempty"`
+	Error        string                 `json:"error,omitempty"`
+}
+
+// Export writes span as JSON line.
+func (we *WriterExporter) Export(span SpanData) error {
+	ws := writerSpan{
+		Name:       span.Name,
+		TraceID:    span.SpanContext.TraceID.String(),
+		SpanID:     span.SpanContext.SpanID.String(),
+		Start:      span.Start,
+		End:        span.End,
+		DurationMs: float64(span.End.Sub(span.Start).Microseconds()) / 1000,
+		Attributes: span.Attributes,
+		Error:      span.Err,
+	}
+
+	if span.ParentSpanID != (SpanID{}) {
+		ws.ParentSpanID = span.ParentSpanID.String()
+	}
+
+	b, err := json.Marshal(ws)
+	if err != nil {
+		return errors.Wrap(err, "marshal span")
+	}
+
+	we.mux.Lock()
+	defer we.mux.Unlock()
+
+	_, err = we.out.Write(append(b, '\n'))
+	return errors.Wrap(err, "write span")
+}
+
+// Shutdown flushes buffered writer and closes file opened by exporter.
+func (we *WriterExporter) Shutdown(ctx context.Context) error {
+	we.mux.Lock()
+	defer we.mux.Unlock()
+
+	if we.file != nil {
+		return errors.Wrap(we.file.Close(), "close trace file")
+	}
+
+	if flusher, ok := we.out.(interface{ Flush() error }); ok {
+		return errors.Wrap(flusher.Flush(), "flush spans")
+	}
+
+	return nil
+}
+
+// OTLP exporter defaults.
+const (
+	defaultBatchSize     int           = 512
+	defaultFlushInterval time.Duration = 5 * time.Second
+)
+
+// OTLPExporter sends finished spans in batches to OTLP/HTTP collector using JSON encoding.
+type OTLPExporter struct {
+	endpoint    string
+	serviceName string
+	client      *http.Client
+
+	mux   sync.Mutex
+	spans []SpanData
+
+	flush chan struct{}
+	done  chan struct{}
+	wg    sync.WaitGroup
+}
+
+// NewOTLPExporter creates a new OTLPExporter instance sending spans to endpoint,
+// e.g. http://localhost:4318/v1/traces, and starts background flushing.
+func NewOTLPExporter(endpoint, serviceName string, onError func(err error)) *OTLPExporter {
+	oe := &OTLPExporter{
+		endpoint:    endpoint,
+		serviceName: serviceName,
+		client:      &http.Client{Timeout: 10 * time.Second},
+		flush:       make(chan struct{}, 1),
+		done:        make(chan struct{}),
+	}
+
+	oe.wg.Add(1)
+	go func() {
+		defer oe.wg.Done()
+
+		ticker := time.NewTicker(defaultFlushInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+			case <-oe.flush:
+			case <-oe.done:
+				return
+			}
+
+			if err := oe.send(context.Background()); err != nil && onError != nil {
+				onError(err)
+			}
+		}
+	}()
+
+	return oe
+}
+
+// Export queues span for sending.
+func (oe *OTLPExporter) Export(span SpanData) error {
+	oe.mux.Lock()
+	oe.spans = append(oe.spans, span)
+	full := len(oe.spans) >= defaultBatchSize
+	oe.mux.Unlock()
+
+	if full {
+		select {
+		case oe.flush <- struct{}{}:
+		default:
+		}
+	}
+
+	return nil
+}
+
+// Shutdown stops background flushing and sends queued spans.
+func (oe *OTLPExporter) Shutdown(ctx context.Context) error {
+	close(oe.done)
+	oe.wg.Wait()
+
+	return oe.send(ctx)
+}
+
+func (oe *OTLPExporter) send(ctx context.Context) error {
+	oe.mux.Lock()
+	spans := oe.spans
+	oe.spans = nil
+	oe.mux.Unlock()
+
+	if len(spans) == 0 {
+		return nil
+	}
+
+	body, err := json.Marshal(oe.request(spans))
+	if err != nil {
+		return errors.Wrap(err, "marshal otlp request")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, oe.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return errors.Wrap(err, "create otlp request")
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := oe.client.Do(req)
+	if err != nil {
+		return errors.Wrap(err, "send otlp request")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return errors.Errorf("send otlp request: unexpected status %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// OTLP JSON encoding types, see opentelemetry-proto trace and common messages.
+type (
+	otlpRequest struct {
+		ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+	}
+
+	otlpResourceSpans struct {
+		Resource   otlpResource     `json:"resource"`
+		ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+	}
+
+	otlpResource struct {
+		Attributes []otlpKeyValue `json:"attributes"`
+	}
+
+	otlpScopeSpans struct {
+		Scope otlpScope  `json:"scope"`
+		Spans []otlpSpan `json:"spans"`
+	}
+
+	otlpScope struct {
+		Name string `json:"name"`
+	}
+
+	otlpSpan struct {
+		TraceID           string         `json:"traceId"`
+		SpanID            string         `json:"spanId"`
+		ParentSpanID      string         `json:"parentSpanId,omitempty"`
+		Name              string         `json:"name"`
+		Kind              int            `json:"kind"`
+		StartTimeUnixNano string         `json:"startTimeUnixNano"`
+		EndTimeUnixNano   string         `json:"endTimeUnixNano"`
+		Attributes        []otlpKeyValue `json:"attributes,omitempty"`
+		Status            otlpStatus     `json:"status"`
+	}
+
+	otlpStatus struct {
+		Code    int    `json:"code"`
+		Message string `json:"message,omitempty"`
+	}
+
+	otlpKeyValue struct {
+		Key   string                 `json:"key"`
+		Value map[string]interface{} `json:"value"`
+	}
+)
+
+// OTLP span kind and status codes.
+const (
+	otlpSpanKindInternal int = 1
+	otlpStatusOk         int = 1
+	otlpStatusError      int = 2
+)
+
+func (oe *OTLPExporter) request(spans []SpanData) otlpRequest {
+	otlpSpans := make([]otlpSpan, 0, len(spans))
+	for _, span := range spans {
+		os := otlpSpan{
+			TraceID:           span.SpanContext.TraceID.String(),
+			SpanID:            span.SpanContext.SpanID.String(),
+			Name:              span.Name,
+			Kind:              otlpSpanKindInternal,
+			StartTimeUnixNano: strconv.FormatInt(span.Start.UnixNano(), 10),
+			EndTimeUnixNano:   strconv.FormatInt(span.End.UnixNano(), 10),
+			Status:            otlpStatus{Code: otlpStatusOk},
+		}
+
+		if span.ParentSpanID != (SpanID{}) {
+			os.ParentSpanID = span.ParentSpanID.String()
+		}
+
+		for key, value := range span.Attributes {
+			os.Attributes = append(os.Attributes, otlpAttribute(key, value))
+		}
+
+		if span.Err != "" {
+			os.Status = otlpStatus{Code: otlpStatusError, Message: span.Err}
+		}
+
+		otlpSpans = append(otlpSpans, os)
+	}
+
+	return otlpRequest{
+		ResourceSpans: []otlpResourceSpans{{
+			Resource: otlpResource{
+				Attributes: []otlpKeyValue{otlpAttribute("service.name", oe.serviceName)},
+			},
+			ScopeSpans: []otlpScopeSpans{{
+				Scope: otlpScope{Name: "github.com/realmallaury/teltech/internal/tracing"},
+				Spans: otlpSpans,
+			}},
+		}},
+	}
+}
+
+func otlpAttribute(key string, value interface{}) otlpKeyValue {
+	switch v := value.(type) {
+	case bool:
+		return otlpKeyValue{Key: key, Value: map[string]interface{}{"boolValue": v}}
+	case int:
+		return otlpKeyValue{Key: key, Value: map[string]interface{}{"intValue": strconv.Itoa(v)}}
+	case float64:
+		return otlpKeyValue{Key: key, Value: map[string]interface{}{"doubleValue": v}}
+	case string:
+		return otlpKeyValue{Key: key, Value: map[string]interface{}{"stringValue": v}}
+	}
+
+	b, _ := json.Marshal(value)
+	return otlpKeyValue{Key: key, Value: map[string]interface{}{"stringValue": string(b)}}
+}
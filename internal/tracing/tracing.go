@@ -0,0 +1,263 @@
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// TraceparentHeader is W3C trace context header.
+const TraceparentHeader string = "traceparent"
+
+// TraceID identifies a trace.
+type TraceID [16]byte
+
+// String returns hex encoded trace id.
+func (t TraceID) String() string {
+	return hex.EncodeToString(t[:])
+}
+
+// SpanID identifies a span.
+type SpanID [8]byte
+
+// String returns hex encoded span id.
+func (s SpanID) String() string {
+	return hex.EncodeToString(s[:])
+}
+
+// SpanContext contains span identifiers propagated between services.
+type SpanContext struct {
+	TraceID TraceID
+	SpanID  SpanID
+	Sampled bool
+}
+
+// IsValid checks weather trace and span ids are set.
+func (sc SpanContext) IsValid() bool {
+	return sc.TraceID != TraceID{} && sc.SpanID != SpanID{}
+}
+
+// Traceparent formats span context as W3C traceparent header value.
+func (sc SpanContext) Traceparent() string {
+	flags := "00"
+	if sc.Sampled {
+		flags = "01"
+	}
+
+	return fmt.Sprintf("00-%s-%s-%s", sc.TraceID, sc.SpanID, flags)
+}
+
+// ParseTraceparent parses W3C traceparent header value.
+func ParseTraceparent(header string) (SpanContext, error) {
+	var sc SpanContext
+
+	header = strings.TrimSpace(header)
+	parts := strings.Split(header, "-")
+	if len(parts) < 4 {
+		return sc, errors.Errorf("traceparent: %s invalid format", header)
+	}
+
+	version, traceID, spanID, flags := parts[0], parts[1], parts[2], parts[3]
+	if len(version) != 2 || version == "ff" || (version == "00" && len(parts) != 4) {
+		return sc, errors.Errorf("traceparent: %s unsupported version", header)
+	}
+
+	if len(traceID) != 32 || len(spanID) != 16 || len(flags) != 2 || strings.ToLower(header) != header {
+		return sc, errors.Errorf("traceparent: %s invalid format", header)
+	}
+
+	if _, err := hex.Decode(sc.TraceID[:], []byte(traceID)); err != nil {
+		return sc, errors.Wrapf(err, "traceparent: %s invalid trace id", header)
+	}
+
+	if _, err := hex.Decode(sc.SpanID[:], []byte(spanID)); err != nil {
+		return sc, errors.Wrapf(err, "traceparent: %s invalid span id", header)
+	}
+
+	flagBytes, err := hex.DecodeString(flags)
+	if err != nil {
+		return sc, errors.Wrapf(err, "traceparent: %s invalid flags", header)
+	}
+
+	if !sc.IsValid() {
+		return sc, errors.Errorf("traceparent: %s zero trace or span id", header)
+	}
+
+	sc.Sampled = flagBytes[0]&1 == 1
+
+	return sc, nil
+}
+
+// SpanData is finished span passed to exporter.
+type SpanData struct {
+	Name         string
+	SpanContext  SpanContext
+	ParentSpanID SpanID
+	Start        time.Time
+	End          time.Time
+	Attributes   map[string]interface{}
+	Err          string
+}
+
+// Exporter sends finished spans to tracing backend.
+type Exporter interface {
+	Export(span SpanData) error
+	Shutdown(ctx context.Context) error
+}
+
+// Tracer creates spans and passes finished spans to exporter.
+type Tracer struct {
+	exporter Exporter
+	onError  func(err error)
+}
+
+// New creates a new Tracer instance, export errors are passed to onError if set.
+func New(exporter Exporter, onError func(err error)) *Tracer {
+	return &Tracer{
+		exporter: exporter,
+		onError:  onError,
+	}
+}
+
+// Shutdown flushes and stops tracer exporter.
+func (t *Tracer) Shutdown(ctx context.Context) error {
+	if t == nil || t.exporter == nil {
+		return nil
+	}
+
+	return t.exporter.Shutdown(ctx)
+}
+
+// Span is a single timed operation within a trace.
+type Span struct {
+	tracer *Tracer
+	mux    sync.Mutex
+	data   SpanData
+	ended  bool
+}
+
+type spanContextKey struct{}
+
+// Start creates a new span which is a child of span or remote span context stored in ctx,
+// nil tracer returns nil span which is safe to use.
+func (t *Tracer) Start(ctx context.Context, name string) (context.Context, *Span) {
+	if t == nil {
+		return ctx, nil
+	}
+
+	parent := SpanContextFromContext(ctx)
+
+	span := &Span{
+		tracer: t,
+		data: SpanData{
+			Name:         name,
+			SpanContext:  SpanContext{SpanID: newSpanID(), Sampled: true},
+			ParentSpanID: parent.SpanID,
+			Start:        time.Now(),
+			Attributes:   make(map[string]interface{}),
+		},
+	}
+
+	if parent.IsValid() {
+		span.data.SpanContext.TraceID = parent.TraceID
+		span.data.SpanContext.Sampled = parent.Sampled
+	} else {
+		span.data.SpanContext.TraceID = newTraceID()
+	}
+
+	return context.WithValue(ctx, spanContextKey{}, span), span
+}
+
+// SpanContext returns span identifiers.
+func (s *Span) SpanContext() SpanContext {
+	if s == nil {
+		return SpanContext{}
+	}
+
+	return s.data.SpanContext
+}
+
+// SetAttribute sets span attribute.
+func (s *Span) SetAttribute(key string, value interface{}) {
+	if s == nil {
+		return
+	}
+
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	s.data.Attributes[key] = value
+}
+
+// SetError marks span as failed.
+func (s *Span) SetError(err error) {
+	if s == nil || err == nil {
+		return
+	}
+
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	s.data.Err = err.Error()
+}
+
+// End finishes span and exports it if sampled.
+func (s *Span) End() {
+	if s == nil {
+		return
+	}
+
+	s.mux.Lock()
+	if s.ended {
+		s.mux.Unlock()
+		return
+	}
+
+	s.ended = true
+	s.data.End = time.Now()
+	data := s.data
+	s.mux.Unlock()
+
+	if !data.SpanContext.Sampled || s.tracer.exporter == nil {
+		return
+	}
+
+	if err := s.tracer.exporter.Export(data); err != nil && s.tracer.onError != nil {
+		s.tracer.onError(err)
+	}
+}
+
+// ContextWithRemoteSpanContext stores span context received from caller in ctx.
+func ContextWithRemoteSpanContext(ctx context.Context, sc SpanContext) context.Context {
+	return context.WithValue(ctx, spanContextKey{}, sc)
+}
+
+// SpanContextFromContext returns span context of current span or remote span context stored in ctx.
+func SpanContextFromContext(ctx context.Context) SpanContext {
+	switch value := ctx.Value(spanContextKey{}).(type) {
+	case *Span:
+		return value.SpanContext()
+	case SpanContext:
+		return value
+	}
+
+	return SpanContext{}
+}
+
+func newTraceID() TraceID {
+	var id TraceID
+	_, _ = rand.Read(id[:])
+	return id
+}
+
+func newSpanID() SpanID {
+	var id SpanID
+	_, _ = rand.Read(id[:])
+	return id
+}
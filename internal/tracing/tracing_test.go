@@ -0,0 +1,161 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseTraceparent(t *testing.T) {
+	assert := assert.New(t)
+
+	tables := []struct {
+		header  string
+		traceID string
+		spanID  string
+		sampled bool
+		errFlag bool
+	}{
+		{"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", "4bf92f3577b34da6a3ce929d0e0e4736", "00f067aa0ba902b7", true, false},
+		{"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00", "4bf92f3577b34da6a3ce929d0e0e4736", "00f067aa0ba902b7", false, false},
+		{"01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra", "4bf92f3577b34da6a3ce929d0e0e4736", "00f067aa0ba902b7", true, false},
+		{"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra", "", "", false, true},
+		{"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", "", "", false, true},
+		{"00-00000000000000000000000000000000-00f067aa0ba902b7-01", "", "", false, true},
+		{"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01", "", "", false, true},
+		{"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba9-01", "", "", false, true},
+		{"", "", "", false, true},
+	}
+
+	for _, table := range tables {
+		sc, err := ParseTraceparent(table.header)
+
+		if table.errFlag {
+			assert.Error(err, "Should be error")
+			continue
+		}
+
+		assert.NoError(err, "Error should be nil")
+		assert.Equal(table.traceID, sc.TraceID.String(), "Values should be the same")
+		assert.Equal(table.spanID, sc.SpanID.String(), "Values should be the same")
+		assert.Equal(table.sampled, sc.Sampled, "Values should be the same")
+	}
+}
+
+func TestStart(t *testing.T) {
+	assert := assert.New(t)
+
+	var buf bytes.Buffer
+	tracer := New(NewWriterExporter(&buf), nil)
+
+	remote, err := ParseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	assert.NoError(err, "Error should be nil")
+
+	ctx, parent := tracer.Start(ContextWithRemoteSpanContext(context.Background(), remote), "parent")
+	_, child := tracer.Start(ctx, "child")
+	child.SetAttribute("cache.hit", true)
+	child.SetError(errors.New("failed"))
+	child.End()
+	parent.End()
+	parent.End()
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Len(lines, 2, "Each span should be exported once")
+
+	var childSpan, parentSpan writerSpan
+	assert.NoError(json.Unmarshal([]byte(lines[0]), &childSpan))
+	assert.NoError(json.Unmarshal([]byte(lines[1]), &parentSpan))
+
+	assert.Equal("4bf92f3577b34da6a3ce929d0e0e4736", parentSpan.TraceID, "Trace id should be propagated")
+	assert.Equal("00f067aa0ba902b7", parentSpan.ParentSpanID, "Remote span should be parent")
+	assert.Equal(parentSpan.TraceID, childSpan.TraceID, "Trace id should be propagated")
+	assert.Equal(parentSpan.SpanID, childSpan.ParentSpanID, "Parent span id should be set")
+	assert.Equal(true, childSpan.Attributes["cache.hit"])
+	assert.Equal("failed", childSpan.Error)
+
+	// Test that nil tracer returns usable nil span
+	var noop *Tracer
+	_, span := noop.Start(context.Background(), "noop")
+	span.SetAttribute("key", "value")
+	span.End()
+	assert.False(span.SpanContext().IsValid())
+}
+
+func TestOTLPExporter(t *testing.T) {
+	assert := assert.New(t)
+
+	requests := make(chan otlpRequest, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+
+		var req otlpRequest
+		_ = json.Unmarshal(body, &req)
+		requests <- req
+	}))
+	defer server.Close()
+
+	exporter := NewOTLPExporter(server.URL, "arithmetic", nil)
+	tracer := New(exporter, nil)
+
+	_, span := tracer.Start(context.Background(), "span")
+	span.SetAttribute("http.status_code", 200)
+	span.End()
+
+	assert.NoError(exporter.Shutdown(context.Background()))
+
+	req := <-requests
+	assert.Len(req.ResourceSpans, 1)
+	assert.Equal("arithmetic", req.ResourceSpans[0].Resource.Attributes[0].Value["stringValue"])
+
+	spans := req.ResourceSpans[0].ScopeSpans[0].Spans
+	assert.Len(spans, 1)
+	assert.Equal("span", spans[0].Name)
+	assert.Equal(span.SpanContext().TraceID.String(), spans[0].TraceID)
+	assert.Equal("http.status_code", spans[0].Attributes[0].Key)
+	assert.Equal("200", spans[0].Attributes[0].Value["intValue"])
+}
+
+func TestWriterExporterShutdown(t *testing.T) {
+	assert := assert.New(t)
+
+	out := &closeRecorder{}
+	assert.NoError(NewWriterExporter(out).Shutdown(context.Background()))
+	assert.False(out.closed, "Writer not opened by exporter should not be closed")
+	assert.True(out.flushed, "Writer should be flushed")
+
+	path := filepath.Join(t.TempDir(), "spans.json")
+	exporter, err := NewFileExporter(path)
+	assert.NoError(err, "Error should be nil")
+	assert.NoError(exporter.Export(SpanData{Name: "span"}))
+	assert.NoError(exporter.Shutdown(context.Background()))
+	assert.Error(exporter.Export(SpanData{Name: "span"}), "File should be closed")
+
+	b, err := ioutil.ReadFile(path)
+	assert.NoError(err, "Error should be nil")
+	assert.Contains(string(b), `"name":"span"`)
+}
+
+type closeRecorder struct {
+	bytes.Buffer
+	closed  bool
+	flushed bool
+}
+
+func (cr *closeRecorder) Flush() error {
+	cr.flushed = true
+	return nil
+}
+
+func (cr *closeRecorder) Close() error {
+	cr.closed = true
+	return nil
+}
@@ -4,12 +4,12 @@ import "strconv"
 
 // Convert converts input string numbers to float.
 func Convert(x, y string) (float64, float64, error) {
-	xVal, err := strconv.ParseFloat(x, 64)
+	xVal, err := ParseNumber(x, DefaultLocale)
 	if err != nil {
 		return 0, 0, err
 	}
 
-	yVal, err := strconv.ParseFloat(y, 64)
+	yVal, err := ParseNumber(y, DefaultLocale)
 	if err != nil {
 		return 0, 0, err
 	}
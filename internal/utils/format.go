@@ -0,0 +1,359 @@
+package utils
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Number notations.
+const (
+	NotationAuto        string = "auto"
+	NotationFixed       string = "fixed"
+	NotationScientific  string = "scientific"
+	NotationEngineering string = "engineering"
+)
+
+// Rounding modes.
+const (
+	RoundHalfEven string = "half-even"
+	RoundHalfUp   string = "half-up"
+	RoundTruncate string = "truncate"
+)
+
+// MaxDecimals is max number of decimal places of formatted number.
+const MaxDecimals int = 20
+
+// Min and max base of formatted integers.
+const (
+	MinBase int = 2
+	MaxBase int = 36
+)
+
+// Locale holds number grouping and decimal separators.
+type Locale struct {
+	Group   string
+	Decimal string
+}
+
+// DefaultLocale doesn't group digits and uses dot as decimal separator.
+var DefaultLocale = Locale{Group: "", Decimal: "."}
+
+// locales are supported locales by lowercase language tag.
+var locales = map[string]Locale{
+	"en":    {Group: ",", Decimal: "."},
+	"de":    {Group: ".", Decimal: ","},
+	"de-ch": {Group: "'", Decimal: "."},
+	"es":    {Group: ".", Decimal: ","},
+	"fr":    {Group: " ", Decimal: ","},
+	"it":    {Group: ".", Decimal: ","},
+	"nl":    {Group: ".", Decimal: ","},
+	"pt":    {Group: ".", Decimal: ","},
+	"ru":    {Group: " ", Decimal: ","},
+}
+
+// LookupLocale returns locale by language tag, e.g. de or de-DE, empty tag returns default locale.
+func LookupLocale(tag string) (Locale, error) {
+	if tag == "" {
+		return DefaultLocale, nil
+	}
+
+	tag = strings.ToLower(strings.Replace(tag, "_", "-", -1))
+	if locale, ok := locales[tag]; ok {
+		return locale, nil
+	}
+
+	if locale, ok := locales[strings.SplitN(tag, "-", 2)[0]]; ok {
+		return locale, nil
+	}
+
+	return Locale{}, errors.Errorf("unsupported locale: %s", tag)
+}
+
+// NumberFormat describes notation, precision, rounding and separators of formatted numbers.
+type NumberFormat struct {
+	Notation string
+	// Decimals is number of decimal places, -1 formats shortest representation.
+	Decimals int
+	Rounding string
+	Locale   Locale
+	// Base is base of integer arithmetic answers, 0 formats answer as float.
+	Base int
+}
+
+// DefaultNumberFormat formats numbers same as FloatToString.
+var DefaultNumberFormat = NumberFormat{
+	Notation: NotationAuto,
+	Decimals: -1,
+	Rounding: RoundHalfEven,
+	Locale:   DefaultLocale,
+}
+
+// ParseNumberFormat returns number format from notation, decimals, rounding, locale and base options,
+// empty options are set to default values.
+func ParseNumberFormat(notation, decimals, rounding, locale, base string) (NumberFormat, error) {
+	nf := DefaultNumberFormat
+
+	switch notation {
+	case "":
+	case NotationAuto, NotationFixed, NotationScientific, NotationEngineering:
+		nf.Notation = notation
+	default:
+		return nf, errors.Errorf("unsupported notation: %s, expected auto, fixed, scientific or engineering", notation)
+	}
+
+	if decimals != "" {
+		value, err := strconv.Atoi(decimals)
+		if err != nil || value < 0 || value > MaxDecimals {
+			return nf, errors.Errorf("decimals value: %s not valid, expected integer from 0 to %d", decimals, MaxDecimals)
+		}
+
+		nf.Decimals = value
+	}
+
+	switch rounding {
+	case "":
+	case RoundHalfEven, RoundHalfUp, RoundTruncate:
+		nf.Rounding = rounding
+	default:
+		return nf, errors.Errorf("unsupported rounding: %s, expected half-even, half-up or truncate", rounding)
+	}
+
+	var err error
+	if nf.Locale, err = LookupLocale(locale); err != nil {
+		return nf, err
+	}
+
+	if base != "" {
+		value, err := strconv.Atoi(base)
+		if err != nil || value < MinBase || value > MaxBase {
+			return nf, errors.Errorf("base value: %s not valid, expected integer from %d to %d", base, MinBase, MaxBase)
+		}
+
+		if notation != "" || decimals != "" || rounding != "" {
+			return nf, errors.New("base can't be combined with notation, decimals or rounding")
+		}
+
+		nf.Base = value
+	}
+
+	return nf, nil
+}
+
+// FormatInt formats integer in base, base 2, 8 and 16 integers have 0b, 0o and 0x prefix
+// and base 10 integers are grouped in locale format.
+func (nf NumberFormat) FormatInt(value *big.Int) string {
+	base := nf.Base
+	if base == 0 {
+		base = 10
+	}
+
+	digits := new(big.Int).Abs(value).Text(base)
+
+	switch base {
+	case 2:
+		digits = "0b" + digits
+	case 8:
+		digits = "0o" + digits
+	case 10:
+		return nf.join(value.Sign() < 0, digits, "", "")
+	case 16:
+		digits = "0x" + digits
+	}
+
+	if value.Sign() < 0 {
+		return "-" + digits
+	}
+
+	return digits
+}
+
+// Format formats f, auto notation with decimals set formats number in fixed notation.
+// Rounding is applied to shortest decimal representation of f, so 2.675 is rounded half-up to 2.68.
+func (nf NumberFormat) Format(f float64) string {
+	if math.IsInf(f, 0) || math.IsNaN(f) {
+		return FloatToString(f)
+	}
+
+	notation := nf.Notation
+	if notation == NotationAuto && nf.Decimals >= 0 {
+		notation = NotationFixed
+	}
+
+	d := newDecimal(f)
+
+	switch notation {
+	case NotationFixed:
+		if nf.Decimals >= 0 {
+			d = d.round(d.exp+nf.Decimals, nf.Rounding)
+		}
+
+		intPart, fracPart := d.split(d.exp, nf.Decimals)
+		return nf.join(d.neg, intPart, fracPart, "")
+
+	case NotationScientific:
+		if nf.Decimals >= 0 {
+			d = d.round(nf.Decimals+1, nf.Rounding)
+		}
+
+		intPart, fracPart := d.split(1, nf.Decimals)
+		return nf.join(d.neg, intPart, fracPart, exponent(d.sciExp()))
+
+	case NotationEngineering:
+		if nf.Decimals >= 0 {
+			d = d.round(d.engDigits()+nf.Decimals, nf.Rounding)
+		}
+
+		intDigits := d.engDigits()
+		intPart, fracPart := d.split(intDigits, nf.Decimals)
+		return nf.join(d.neg, intPart, fracPart, exponent(d.sciExp()-intDigits+1))
+	}
+
+	s := FloatToString(f)
+	neg := strings.HasPrefix(s, "-")
+	s = strings.TrimPrefix(s, "-")
+
+	var exp string
+	if i := strings.IndexByte(s, 'e'); i >= 0 {
+		s, exp = s[:i], s[i:]
+	}
+
+	parts := strings.SplitN(s, ".", 2)
+	if len(parts) == 1 {
+		return nf.join(neg, parts[0], "", exp)
+	}
+
+	return nf.join(neg, parts[0], parts[1], exp)
+}
+
+// join joins sign, integer part with grouped digits, fraction part and exponent.
+func (nf NumberFormat) join(neg bool, intPart, fracPart, exp string) string {
+	var b strings.Builder
+	if neg {
+		b.WriteByte('-')
+	}
+
+	for i := range intPart {
+		if i > 0 && (len(intPart)-i)%3 == 0 {
+			b.WriteString(nf.Locale.Group)
+		}
+
+		b.WriteByte(intPart[i])
+	}
+
+	if fracPart != "" {
+		b.WriteString(nf.Locale.Decimal)
+		b.WriteString(fracPart)
+	}
+
+	b.WriteString(exp)
+	return b.String()
+}
+
+func exponent(exp int) string {
+	return fmt.Sprintf("e%+03d", exp)
+}
+
+// decimal is decimal representation of float, value is 0.digits * 10^exp.
+type decimal struct {
+	neg bool
+	// digits are significant digits without leading and trailing zeros, empty for zero.
+	digits string
+	exp    int
+}
+
+func newDecimal(f float64) decimal {
+	s := strconv.FormatFloat(math.Abs(f), 'e', -1, 64)
+	i := strings.IndexByte(s, 'e')
+
+	digits := strings.TrimRight(strings.Replace(s[:i], ".", "", 1), "0")
+	if digits == "" {
+		return decimal{}
+	}
+
+	exp, _ := strconv.Atoi(s[i+1:])
+	return decimal{neg: f < 0, digits: digits, exp: exp + 1}
+}
+
+// round rounds decimal to n significant digits.
+func (d decimal) round(n int, mode string) decimal {
+	if n >= len(d.digits) {
+		return d
+	}
+
+	if n < 0 {
+		return decimal{}
+	}
+
+	var up bool
+	switch mode {
+	case RoundHalfUp:
+		up = d.digits[n] >= '5'
+	case RoundHalfEven:
+		// Digits have no trailing zeros, so any digit after n means value is above half.
+		up = d.digits[n] > '5' || d.digits[n] == '5' && (len(d.digits) > n+1 || n > 0 && (d.digits[n-1]-'0')%2 == 1)
+	}
+
+	digits := []byte(d.digits[:n])
+	if up {
+		i := n - 1
+		for ; i >= 0 && digits[i] == '9'; i-- {
+		}
+
+		if i < 0 {
+			return decimal{neg: d.neg, digits: "1", exp: d.exp + 1}
+		}
+
+		digits[i]++
+		digits = digits[:i+1]
+	}
+
+	rounded := decimal{neg: d.neg, digits: strings.TrimRight(string(digits), "0"), exp: d.exp}
+	if rounded.digits == "" {
+		return decimal{}
+	}
+
+	return rounded
+}
+
+// split returns integer and fraction part of decimal with point digits before decimal point,
+// fraction part is padded with zeros to decimals places.
+func (d decimal) split(point, decimals int) (string, string) {
+	var intPart, fracPart string
+
+	switch {
+	case d.digits == "":
+		intPart = "0"
+	case point <= 0:
+		intPart, fracPart = "0", strings.Repeat("0", -point)+d.digits
+	case point >= len(d.digits):
+		intPart = d.digits + strings.Repeat("0", point-len(d.digits))
+	default:
+		intPart, fracPart = d.digits[:point], d.digits[point:]
+	}
+
+	if decimals > len(fracPart) {
+		fracPart += strings.Repeat("0", decimals-len(fracPart))
+	}
+
+	return intPart, fracPart
+}
+
+// sciExp returns exponent of decimal in scientific notation.
+func (d decimal) sciExp() int {
+	if d.digits == "" {
+		return 0
+	}
+
+	return d.exp - 1
+}
+
+// engDigits returns number of integer digits of decimal in engineering notation.
+func (d decimal) engDigits() int {
+	exp := d.sciExp()
+	return (exp%3+3)%3 + 1
+}
@@ -0,0 +1,106 @@
+package utils
+
+import (
+	"math"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNumberFormat(t *testing.T) {
+	assert := assert.New(t)
+
+	tests := []struct {
+		name     string
+		notation string
+		decimals string
+		rounding string
+		locale   string
+		value    float64
+		expected string
+	}{
+		{"default", "", "", "", "", 1e21, "1e+21"},
+		{"default fraction", "", "", "", "", -0.1, "-0.1"},
+		{"auto with locale", "", "", "", "de", 1234.5, "1.234,5"},
+		{"auto with decimals", "auto", "2", "", "", 1.005, "1.00"},
+		{"fixed", "fixed", "", "", "", 1e21, "1000000000000000000000"},
+		{"fixed small", "fixed", "", "", "", 1.5e-7, "0.00000015"},
+		{"fixed half-even down", "fixed", "0", "half-even", "", 2.5, "2"},
+		{"fixed half-even up", "fixed", "0", "half-even", "", 3.5, "4"},
+		{"fixed half-even above half", "fixed", "0", "half-even", "", 2.51, "3"},
+		{"fixed half-up", "fixed", "0", "half-up", "", 2.5, "3"},
+		{"fixed half-up decimal", "fixed", "2", "half-up", "", 2.675, "2.68"},
+		{"fixed half-up carry", "fixed", "2", "half-up", "", 9.995, "10.00"},
+		{"fixed truncate", "fixed", "2", "truncate", "", -2.679, "-2.67"},
+		{"fixed rounded to zero", "fixed", "2", "", "", -0.001, "0.00"},
+		{"fixed below half unit", "fixed", "1", "half-up", "", 0.05, "0.1"},
+		{"fixed padded", "fixed", "3", "", "", 1.5, "1.500"},
+		{"fixed zero", "fixed", "2", "", "", 0, "0.00"},
+		{"fixed en", "fixed", "2", "", "en-US", 1234567.891, "1,234,567.89"},
+		{"fixed de", "fixed", "2", "", "de_DE", -1234567.891, "-1.234.567,89"},
+		{"fixed de-ch", "fixed", "", "", "de-CH", 1234567.5, "1'234'567.5"},
+		{"scientific", "scientific", "", "", "", 123456, "1.23456e+05"},
+		{"scientific decimals", "scientific", "2", "", "", 123456, "1.23e+05"},
+		{"scientific carry", "scientific", "1", "", "", 9.96e-5, "1.0e-04"},
+		{"scientific zero", "scientific", "1", "", "", 0, "0.0e+00"},
+		{"scientific locale", "scientific", "2", "", "fr", 1500, "1,50e+03"},
+		{"engineering", "engineering", "", "", "", 123456, "123.456e+03"},
+		{"engineering small", "engineering", "", "", "", 0.00012, "120e-06"},
+		{"engineering decimals", "engineering", "1", "", "", 12345, "12.3e+03"},
+		{"engineering carry", "engineering", "1", "", "", 999.96, "1.0e+03"},
+		{"infinity", "fixed", "2", "", "", math.Inf(1), "+Inf"},
+	}
+
+	for _, test := range tests {
+		nf, err := ParseNumberFormat(test.notation, test.decimals, test.rounding, test.locale, "")
+		assert.NoError(err, test.name)
+		assert.Equal(test.expected, nf.Format(test.value), test.name)
+	}
+
+	// Test integer formatting
+	integers := []struct {
+		base     string
+		locale   string
+		value    int64
+		expected string
+	}{
+		{"", "", -1234567, "-1234567"},
+		{"10", "de", 1234567, "1.234.567"},
+		{"2", "", 10, "0b1010"},
+		{"8", "", -15, "-0o17"},
+		{"16", "en", 65535, "0xffff"},
+		{"36", "", 71, "1z"},
+	}
+
+	for _, test := range integers {
+		nf, err := ParseNumberFormat("", "", "", test.locale, test.base)
+		assert.NoError(err, test.expected)
+		assert.Equal(test.expected, nf.FormatInt(big.NewInt(test.value)), test.expected)
+	}
+
+	// Test invalid options
+	invalid := []struct {
+		notation string
+		decimals string
+		rounding string
+		locale   string
+		base     string
+		err      string
+	}{
+		{"hex", "", "", "", "", "unsupported notation: hex, expected auto, fixed, scientific or engineering"},
+		{"", "-1", "", "", "", "decimals value: -1 not valid, expected integer from 0 to 20"},
+		{"", "21", "", "", "", "decimals value: 21 not valid, expected integer from 0 to 20"},
+		{"", "two", "", "", "", "decimals value: two not valid, expected integer from 0 to 20"},
+		{"", "", "ceiling", "", "", "unsupported rounding: ceiling, expected half-even, half-up or truncate"},
+		{"", "", "", "xx", "", "unsupported locale: xx"},
+		{"", "", "", "", "1", "base value: 1 not valid, expected integer from 2 to 36"},
+		{"", "", "", "", "0x10", "base value: 0x10 not valid, expected integer from 2 to 36"},
+		{"fixed", "", "", "", "16", "base can't be combined with notation, decimals or rounding"},
+	}
+
+	for _, test := range invalid {
+		_, err := ParseNumberFormat(test.notation, test.decimals, test.rounding, test.locale, test.base)
+		assert.EqualError(err, test.err)
+	}
+}
@@ -0,0 +1,98 @@
+package utils
+
+import (
+	"fmt"
+	"math/big"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// MaxIntBits is max size of integer operands.
+const MaxIntBits int = 4096
+
+const (
+	radixIntPattern string = "^[-+]?0(?:[xX][0-9a-fA-F]+|[oO][0-7]+|[bB][01]+)$"
+
+	invalidInteger string = "%s value: %s not valid integer, non-decimal operands and bases require integer operands"
+)
+
+var rxRadixInt = regexp.MustCompile(radixIntPattern)
+
+// IsRadixInt checks weather s is integer with 0x, 0o or 0b prefix.
+func IsRadixInt(s string) bool {
+	return rxRadixInt.MatchString(s)
+}
+
+// ParseInt parses decimal integer or integer with 0x, 0o or 0b prefix.
+func ParseInt(s string) (*big.Int, error) {
+	if !rxInt.MatchString(s) && !IsRadixInt(s) {
+		return nil, errors.Errorf("value: %s not valid integer", s)
+	}
+
+	// Decimal pattern doesn't allow leading zeros, so base prefix is always explicit.
+	value, ok := new(big.Int).SetString(s, 0)
+	if !ok {
+		return nil, errors.Errorf("value: %s not valid integer", s)
+	}
+
+	if value.BitLen() > MaxIntBits {
+		return nil, errors.Errorf("value: %s out of range, max %d bits", s, MaxIntBits)
+	}
+
+	return value, nil
+}
+
+// ConvertInt converts input string integers to big integers.
+func ConvertInt(x, y string) (*big.Int, *big.Int, error) {
+	xVal, err := ParseInt(x)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	yVal, err := ParseInt(y)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return xVal, yVal, nil
+}
+
+// IntegerXY converts integer x and y to hexadecimal operands of integer arithmetic,
+// so they don't share cache with float operands.
+func IntegerXY(x, y string) (string, string, error) {
+	xVal, err := ParseInt(x)
+	if err != nil {
+		return "", "", fmt.Errorf(invalidInteger, "x", x)
+	}
+
+	yVal, err := ParseInt(y)
+	if err != nil {
+		return "", "", fmt.Errorf(invalidInteger, "y", y)
+	}
+
+	return hexInt(xVal), hexInt(yVal), nil
+}
+
+func hexInt(value *big.Int) string {
+	if value.Sign() < 0 {
+		return "-0x" + new(big.Int).Abs(value).Text(16)
+	}
+
+	return "0x" + value.Text(16)
+}
+
+// NormalizeOperand returns integer with 0x, 0o or 0b prefix in lowercase, fraction
+// or number normalized in locale format.
+func NormalizeOperand(s string, locale Locale) (string, bool) {
+	if IsRadixInt(s) {
+		return strings.ToLower(s), true
+	}
+
+	if IsFraction(s) {
+		return s, true
+	}
+
+	return NormalizeNumber(s, locale)
+}
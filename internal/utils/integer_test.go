@@ -0,0 +1,46 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIntegerXY(t *testing.T) {
+	assert := assert.New(t)
+
+	tests := []struct {
+		x   string
+		y   string
+		hx  string
+		hy  string
+		err string
+	}{
+		{"0xFF", "-0b1010", "0xff", "-0xa", ""},
+		{"+0o17", "255", "0xf", "0xff", ""},
+		{"0x", "1", "", "", "x value: 0x not valid integer, non-decimal operands and bases require integer operands"},
+		{"1", "1e3", "", "", "y value: 1e3 not valid integer, non-decimal operands and bases require integer operands"},
+		{"0b102", "1", "", "", "x value: 0b102 not valid integer, non-decimal operands and bases require integer operands"},
+	}
+
+	for _, test := range tests {
+		x, y, err := IntegerXY(test.x, test.y)
+		if test.err != "" {
+			assert.EqualError(err, test.err)
+			continue
+		}
+
+		assert.NoError(err)
+		assert.Equal(test.hx, x)
+		assert.Equal(test.hy, y)
+	}
+
+	// Test that validator accepts integer operands only with integers
+	ok, err := IsXYValid("0xff", "0o7")
+	assert.True(ok)
+	assert.NoError(err)
+
+	ok, err = IsXYValid("0xff", "0.5")
+	assert.False(ok)
+	assert.EqualError(err, "y value: 0.5 not valid integer, non-decimal operands and bases require integer operands")
+}
@@ -0,0 +1,92 @@
+package utils
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// ParseNumber parses number in locale format to float.
+func ParseNumber(s string, locale Locale) (float64, error) {
+	normalized, ok := NormalizeNumber(s, locale)
+	if !ok {
+		return 0, errors.Errorf("value: %s not valid number", s)
+	}
+
+	return strconv.ParseFloat(normalized, 64)
+}
+
+// NormalizeNumber converts integer or float in locale format, e.g. 1.000,25 in de locale,
+// to format without grouping separators and with dot decimal separator.
+// Grouping separators are accepted only between groups of three integer digits.
+func NormalizeNumber(s string, locale Locale) (string, bool) {
+	number := s
+
+	var exp string
+	if i := strings.IndexAny(number, "eE"); i >= 0 {
+		number, exp = number[:i], number[i:]
+	}
+
+	var sign string
+	if strings.HasPrefix(number, "-") || strings.HasPrefix(number, "+") {
+		sign, number = number[:1], number[1:]
+	}
+
+	parts := strings.SplitN(number, locale.Decimal, 2)
+
+	intPart := parts[0]
+	if locale.Group != "" && strings.Contains(intPart, locale.Group) {
+		groups := strings.Split(intPart, locale.Group)
+		if len(groups[0]) == 0 || len(groups[0]) > 3 {
+			return "", false
+		}
+
+		for _, group := range groups[1:] {
+			if len(group) != 3 {
+				return "", false
+			}
+		}
+
+		intPart = strings.Join(groups, "")
+	}
+
+	normalized := sign + intPart
+	if len(parts) == 2 {
+		normalized += "." + parts[1]
+	}
+
+	normalized += exp
+	if !isIntOrFloat(normalized) {
+		return "", false
+	}
+
+	return normalized, true
+}
+
+// NormalizeXY validates and normalizes x and y in locale format, errors are same as IsXYValid errors.
+// When operand has 0x, 0o or 0b prefix, both operands are converted to integer operands
+// and when operand is fraction, both operands are converted to fractions.
+func NormalizeXY(x, y string, locale Locale) (string, string, error) {
+	normalizedX, validX := NormalizeOperand(x, locale)
+	normalizedY, validY := NormalizeOperand(y, locale)
+
+	if !validX && !validY {
+		return "", "", fmt.Errorf(invalidValues, "x", x, "y", y)
+	} else if !validX {
+		return "", "", fmt.Errorf(invalidValue, "x", x)
+	} else if !validY {
+		return "", "", fmt.Errorf(invalidValue, "y", y)
+	}
+
+	if IsRadixInt(normalizedX) || IsRadixInt(normalizedY) {
+		return IntegerXY(normalizedX, normalizedY)
+	}
+
+	if IsFraction(normalizedX) || IsFraction(normalizedY) {
+		return RationalXY(normalizedX, normalizedY)
+	}
+
+	return normalizedX, normalizedY, nil
+}
@@ -0,0 +1,57 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeNumber(t *testing.T) {
+	assert := assert.New(t)
+
+	tests := []struct {
+		value      string
+		locale     string
+		normalized string
+		valid      bool
+	}{
+		{"1.5", "", "1.5", true},
+		{"-1e5", "", "-1e5", true},
+		{"1,5", "", "", false},
+		{"1.000,25", "de", "1000.25", true},
+		{"1,5", "de", "1.5", true},
+		{"-1.234.567", "de", "-1234567", true},
+		{"1,5e3", "de", "1.5e3", true},
+		{"1.5", "de", "", false},
+		{"1.00,5", "de", "", false},
+		{"1234.567", "de", "", false},
+		{".000", "de", "", false},
+		{"1,000.25", "en", "1000.25", true},
+		{"1000.25", "en", "1000.25", true},
+		{"1,5", "en", "", false},
+		{"1'000.5", "de-CH", "1000.5", true},
+		{"1 000,5", "fr", "1000.5", true},
+		{"1,2,3", "fr", "", false},
+	}
+
+	for _, test := range tests {
+		locale, err := LookupLocale(test.locale)
+		assert.NoError(err, test.value)
+
+		normalized, valid := NormalizeNumber(test.value, locale)
+		assert.Equal(test.valid, valid, test.value)
+		assert.Equal(test.normalized, normalized, test.value)
+	}
+
+	de, _ := LookupLocale("de")
+
+	value, err := ParseNumber("1.000,25", de)
+	assert.NoError(err)
+	assert.Equal(1000.25, value)
+
+	_, err = ParseNumber("1.5", de)
+	assert.EqualError(err, "value: 1.5 not valid number")
+
+	_, _, err = NormalizeXY("1.5", "2,5", de)
+	assert.EqualError(err, "x value: 1.5 not valid number")
+}
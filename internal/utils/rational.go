@@ -0,0 +1,96 @@
+package utils
+
+import (
+	"math/big"
+	"regexp"
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+const fractionPattern string = "^[-+]?(?:0|[1-9][0-9]*)/[1-9][0-9]*$"
+
+var rxFraction = regexp.MustCompile(fractionPattern)
+
+// IsFraction checks weather s is fraction with decimal numerator and denominator, e.g. 1/3.
+func IsFraction(s string) bool {
+	return rxFraction.MatchString(s)
+}
+
+// ParseRat parses fraction, decimal integer or float to rational number.
+func ParseRat(s string) (*big.Rat, error) {
+	if !IsFraction(s) {
+		if !isIntOrFloat(s) {
+			return nil, errors.Errorf("value: %s not valid fraction", s)
+		}
+
+		// Float operands are limited to float range same as float arithmetic, so exponents like 1e400
+		// or 1e-400 are not expanded to huge numerators or denominators.
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil || f == 0 && !isZero(s) {
+			return nil, errors.Errorf("value: %s out of range", s)
+		}
+	}
+
+	value, ok := new(big.Rat).SetString(s)
+	if !ok {
+		return nil, errors.Errorf("value: %s not valid fraction", s)
+	}
+
+	if value.Num().BitLen() > MaxIntBits || value.Denom().BitLen() > MaxIntBits {
+		return nil, errors.Errorf("value: %s out of range, max %d bits", s, MaxIntBits)
+	}
+
+	return value, nil
+}
+
+// ConvertRat converts input string numbers to rational numbers.
+func ConvertRat(x, y string) (*big.Rat, *big.Rat, error) {
+	xVal, err := ParseRat(x)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	yVal, err := ParseRat(y)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return xVal, yVal, nil
+}
+
+// RationalXY converts x and y to fraction operands of rational arithmetic,
+// so they don't share cache with float operands.
+func RationalXY(x, y string) (string, string, error) {
+	xVal, err := ParseRat(x)
+	if err != nil {
+		return "", "", errors.Errorf("x %v", err)
+	}
+
+	yVal, err := ParseRat(y)
+	if err != nil {
+		return "", "", errors.Errorf("y %v", err)
+	}
+
+	return FractionString(xVal), FractionString(yVal), nil
+}
+
+// FractionString formats rational number as fraction, integers have denominator 1.
+func FractionString(value *big.Rat) string {
+	return value.Num().String() + "/" + value.Denom().String()
+}
+
+// isZero checks weather decimal number s has no non-zero digits in mantissa.
+func isZero(s string) bool {
+	for _, ch := range s {
+		if ch == 'e' || ch == 'E' {
+			break
+		}
+
+		if ch >= '1' && ch <= '9' {
+			return false
+		}
+	}
+
+	return true
+}
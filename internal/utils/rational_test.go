@@ -0,0 +1,86 @@
+package utils
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseRat(t *testing.T) {
+	assert := assert.New(t)
+
+	tables := []struct {
+		s     string
+		value string
+		err   string
+	}{
+		{"1/3", "1/3", ""},
+		{"-2/4", "-1/2", ""},
+		{"0/5", "0/1", ""},
+		{"1.25", "5/4", ""},
+		{"-3", "-3/1", ""},
+		{"0.0e-400", "0/1", ""},
+		{"1/0", "", "value: 1/0 not valid fraction"},
+		{"1/3/4", "", "value: 1/3/4 not valid fraction"},
+		{"1e400", "", "value: 1e400 out of range"},
+		{"1e-400", "", "value: 1e-400 out of range"},
+	}
+
+	for _, table := range tables {
+		value, err := ParseRat(table.s)
+		if table.err != "" {
+			assert.EqualError(err, table.err, table.s)
+			continue
+		}
+
+		assert.NoError(err, table.s)
+		assert.Equal(table.value, value.String(), table.s)
+	}
+}
+
+func TestRationalXY(t *testing.T) {
+	assert := assert.New(t)
+
+	tables := []struct {
+		x   string
+		y   string
+		fx  string
+		fy  string
+		err string
+	}{
+		{"1/3", "0.5", "1/3", "1/2", ""},
+		{"2", "-6/4", "2/1", "-3/2", ""},
+		{"1/a", "1", "", "", "x value: 1/a not valid fraction"},
+		{"1", "1e-400", "", "", "y value: 1e-400 out of range"},
+	}
+
+	for _, table := range tables {
+		x, y, err := RationalXY(table.x, table.y)
+		if table.err != "" {
+			assert.EqualError(err, table.err)
+			continue
+		}
+
+		assert.NoError(err)
+		assert.Equal(table.fx, x)
+		assert.Equal(table.fy, y)
+	}
+}
+
+func TestFractionString(t *testing.T) {
+	assert := assert.New(t)
+
+	tables := []struct {
+		value *big.Rat
+		s     string
+	}{
+		{big.NewRat(1, 3), "1/3"},
+		{big.NewRat(-4, 2), "-2/1"},
+		{big.NewRat(0, 7), "0/1"},
+	}
+
+	for _, table := range tables {
+		assert.Equal(table.s, FractionString(table.value))
+	}
+}
@@ -1,9 +1,6 @@
 package utils
 
-import (
-	"fmt"
-	"regexp"
-)
+import "regexp"
 
 const (
 	intPattern  string = "^(?:[-+]?(?:0|[1-9][0-9]*))$"
@@ -18,17 +15,12 @@ var (
 	rxFloat = regexp.MustCompile(floatPatern)
 )
 
-// IsXYValid checks weather x and y are valid integer or float values.
+// IsXYValid checks weather x and y are valid integer or float values,
+// integers can have 0x, 0o or 0b prefix when both operands are integers
+// and fractions, e.g. 1/3, can be combined with decimal operands.
 func IsXYValid(x, y string) (bool, error) {
-	validX := isIntOrFloat(x)
-	validY := isIntOrFloat(y)
-
-	if !validX && !validY {
-		return false, fmt.Errorf(invalidValues, "x", x, "y", y)
-	} else if !validX {
-		return false, fmt.Errorf(invalidValue, "x", x)
-	} else if !validY {
-		return false, fmt.Errorf(invalidValue, "y", y)
+	if _, _, err := NormalizeXY(x, y, DefaultLocale); err != nil {
+		return false, err
 	}
 
 	return true, nil
@@ -0,0 +1,25 @@
+package version
+
+// Build information, set at link time with
+// -ldflags "-X github.com/realmallaury/teltech/internal/version.Version=1.0.0".
+var (
+	Version   = "dev"
+	Commit    = "unknown"
+	BuildTime = "unknown"
+)
+
+// Info contains service build information.
+type Info struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	BuildTime string `json:"build_time"`
+}
+
+// Get returns service build information.
+func Get() Info {
+	return Info{
+		Version:   Version,
+		Commit:    Commit,
+		BuildTime: BuildTime,
+	}
+}